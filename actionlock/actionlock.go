@@ -0,0 +1,166 @@
+package actionlock
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the lock file checked into the project next to its workflows.
+const FileName = "autoflow.lock"
+
+var (
+	commitSHA    = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	workflowDirs = []string{".github/workflows", ".gitea/workflows", ".forgejo/workflows"}
+)
+
+// File maps action references such as "actions/checkout@v4" to the full
+// commit SHA the reference resolved to.
+type File struct {
+	Actions map[string]string `yaml:"actions"`
+}
+
+func Load(dir string) (*File, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var lock File
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+	if lock.Actions == nil {
+		lock.Actions = make(map[string]string)
+	}
+	return &lock, nil
+}
+
+func (f *File) Save(dir string) error {
+	data, err := yaml.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", FileName, err)
+	}
+
+	header := "# Generated by `autoflow lock`. Maps action references to the commits they resolve to.\n"
+	if err := os.WriteFile(filepath.Join(dir, FileName), append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", FileName, err)
+	}
+	return nil
+}
+
+// Pin replaces the ref of an action with its locked commit SHA and returns
+// the pinned reference together with the original ref.
+func (f *File) Pin(uses string) (string, string, error) {
+	action, ref, found := strings.Cut(uses, "@")
+	if !found || commitSHA.MatchString(ref) {
+		return uses, "", nil
+	}
+
+	sha, ok := f.Actions[uses]
+	if !ok {
+		return "", "", fmt.Errorf("action %s is not pinned in %s, run `autoflow lock --action %s` to resolve it", uses, FileName, uses)
+	}
+	return action + "@" + sha, ref, nil
+}
+
+// Resolve looks up the commit an action reference points to in a local
+// mirror, where each action repository is cloned to <mirror>/<owner>/<repo>
+// or <mirror>/<owner>/<repo>.git.
+func Resolve(mirror, uses string) (string, error) {
+	action, ref, found := strings.Cut(uses, "@")
+	if !found {
+		return "", fmt.Errorf("action %s has no ref", uses)
+	}
+
+	parts := strings.Split(action, "/")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("action %s is not an owner/repo reference", uses)
+	}
+
+	repo := filepath.Join(mirror, parts[0], parts[1])
+	if _, err := os.Stat(repo); err != nil {
+		repo += ".git"
+		if _, err := os.Stat(repo); err != nil {
+			return "", fmt.Errorf("no mirror of %s/%s in %s", parts[0], parts[1], mirror)
+		}
+	}
+
+	out, err := exec.Command("git", "-C", repo, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s in %s: %w", uses, repo, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Scan collects the action references used by the workflows under dir. Steps
+// that are already pinned are reported by the ref kept in their comment.
+func Scan(dir string) ([]string, error) {
+	var refs []string
+	for _, workflowDir := range workflowDirs {
+		files, err := filepath.Glob(filepath.Join(dir, workflowDir, "*.y*ml"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, file := range files {
+			found, err := scanFile(file)
+			if err != nil {
+				return nil, err
+			}
+			for _, ref := range found {
+				if !slices.Contains(refs, ref) {
+					refs = append(refs, ref)
+				}
+			}
+		}
+	}
+
+	slices.Sort(refs)
+	return refs, nil
+}
+
+func scanFile(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var refs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimLeft(strings.TrimSpace(scanner.Text()), "- ")
+		value, found := strings.CutPrefix(line, "uses:")
+		if !found {
+			continue
+		}
+
+		value, comment, _ := strings.Cut(value, "#")
+		uses := strings.Trim(strings.TrimSpace(value), `"'`)
+		uses = strings.TrimPrefix(uses, "https://github.com/")
+		if strings.HasPrefix(uses, "./") || strings.Contains(uses, "://") {
+			continue
+		}
+
+		action, ref, found := strings.Cut(uses, "@")
+		if !found {
+			continue
+		}
+		if commitSHA.MatchString(ref) {
+			ref = strings.TrimSpace(comment)
+			if ref == "" {
+				continue
+			}
+		}
+		refs = append(refs, action+"@"+ref)
+	}
+	return refs, scanner.Err()
+}
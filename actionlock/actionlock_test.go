@@ -0,0 +1,140 @@
+package actionlock
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const checkoutSHA = "11bd71901bbe5b1630ceea73d27597364c9af683"
+
+func TestLoadSave(t *testing.T) {
+	dir := t.TempDir()
+	lock := &File{Actions: map[string]string{"actions/checkout@v4": checkoutSHA}}
+	if err := lock.Save(dir); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got := loaded.Actions["actions/checkout@v4"]; got != checkoutSHA {
+		t.Errorf("Actions[actions/checkout@v4] = %q, want %q", got, checkoutSHA)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, FileName), []byte("# Empty.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if empty.Actions == nil {
+		t.Error("Load() of an empty lock file left Actions nil")
+	}
+}
+
+func TestPin(t *testing.T) {
+	lock := &File{Actions: map[string]string{"actions/checkout@v4": checkoutSHA}}
+	tests := []struct {
+		uses    string
+		pinned  string
+		version string
+		wantErr bool
+	}{
+		{uses: "actions/checkout@v4", pinned: "actions/checkout@" + checkoutSHA, version: "v4"},
+		{uses: "actions/checkout@" + checkoutSHA, pinned: "actions/checkout@" + checkoutSHA},
+		{uses: "./.github/actions/setup", pinned: "./.github/actions/setup"},
+		{uses: "actions/setup-go@v5", wantErr: true},
+	}
+	for _, test := range tests {
+		pinned, version, err := lock.Pin(test.uses)
+		if (err != nil) != test.wantErr {
+			t.Errorf("Pin(%q) error = %v, want error %v", test.uses, err, test.wantErr)
+			continue
+		}
+		if pinned != test.pinned || version != test.version {
+			t.Errorf("Pin(%q) = %q, %q, want %q, %q", test.uses, pinned, version, test.pinned, test.version)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".github/workflows/ci.yml": `jobs:
+    test:
+        steps:
+            - uses: actions/checkout@` + checkoutSHA + ` # v4
+            - uses: "actions/setup-node@v4"
+            - uses: ./.github/actions/local
+            - uses: docker://alpine:3
+            - name: Pinned without a comment
+              uses: actions/cache@` + checkoutSHA + `
+`,
+		".gitea/workflows/ci.yaml":   "jobs:\n  test:\n    steps:\n      - uses: https://github.com/actions/setup-node@v4\n      - uses: actions/setup-go@v5\n",
+		".github/workflows/notes.md": "uses: ignored/action@v1\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refs, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	want := []string{"actions/checkout@v4", "actions/setup-go@v5", "actions/setup-node@v4"}
+	if !slices.Equal(refs, want) {
+		t.Errorf("Scan() = %q, want %q", refs, want)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	mirror := t.TempDir()
+	repo := filepath.Join(mirror, "actions", "checkout")
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "--quiet")
+	git("commit", "--quiet", "--allow-empty", "--message", "Initial commit")
+	git("tag", "--annotate", "v4", "--message", "v4")
+	sha := git("rev-parse", "HEAD")
+
+	got, err := Resolve(mirror, "actions/checkout@v4")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if got != sha {
+		t.Errorf("Resolve() = %q, want the tagged commit %q", got, sha)
+	}
+
+	for _, uses := range []string{"actions/checkout", "checkout@v4", "actions/checkout@v5", "actions/cache@v4"} {
+		if _, err := Resolve(mirror, uses); err == nil {
+			t.Errorf("Resolve(%q) succeeded, want an error", uses)
+		}
+	}
+}
@@ -2,19 +2,23 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/fang"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/zraisan/AutoFlow/actionlock"
+	"github.com/zraisan/AutoFlow/executors"
 	"github.com/zraisan/AutoFlow/registry"
 	"github.com/spf13/cobra"
 
-	_ "github.com/zraisan/AutoFlow/executors"
 	_ "github.com/zraisan/AutoFlow/extractors"
 )
 
@@ -23,6 +27,7 @@ type Screen string
 const (
 	ScreenLanding   Screen = "landing"
 	ScreenExecutor  Screen = "executor"
+	ScreenTriggers  Screen = "triggers"
 	ScreenExtractor Screen = "extractor"
 	ScreenDirectory Screen = "directory"
 	ScreenResult    Screen = "result"
@@ -47,6 +52,17 @@ type Extractor struct {
 	Selected int
 }
 
+// Triggers holds the trigger toggles followed by the text fields that refine
+// them: tag patterns, the cron schedule, dispatch inputs and path filters.
+// The cursor moves through the toggles, then the fields.
+type Triggers struct {
+	Choices []string
+	Cursor  int
+	Enabled []bool
+	Labels  []string
+	Fields  []textinput.Model
+}
+
 type Directory struct {
 	Value      textinput.Model
 	Choices    []string
@@ -60,9 +76,12 @@ type Model struct {
 	screen    Screen
 	landing   Landing
 	executor  Executor
+	triggers  Triggers
 	extractor Extractor
 	directory Directory
 	output    string
+	secrets   []registry.EnvVar
+	warnings  []string
 	width     int
 	height    int
 }
@@ -113,6 +132,214 @@ var listCmd = &cobra.Command{
 	},
 }
 
+var generateCmd = &cobra.Command{
+	Use:   "generate [path]",
+	Short: "generate a workflow",
+	Long:  "generate a workflow for the project at path without the interactive interface",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		flags := cmd.Flags()
+		extractorName, _ := flags.GetString("extractor")
+		executorName, _ := flags.GetString("executor")
+		name, _ := flags.GetString("name")
+		osList, _ := flags.GetStringSlice("os")
+		failFast, _ := flags.GetBool("fail-fast")
+		layout, _ := flags.GetString("layout")
+		if layout != registry.LayoutSingle && layout != registry.LayoutSplit {
+			return fmt.Errorf("invalid layout %q, expected %s or %s", layout, registry.LayoutSingle, registry.LayoutSplit)
+		}
+		deployWhen, _ := flags.GetString("deploy-when")
+		if deployWhen != registry.DeployManual && deployWhen != registry.DeployDelayed && deployWhen != registry.DeployOnSuccess {
+			return fmt.Errorf("invalid deploy mode %q, expected %s, %s or %s", deployWhen, registry.DeployManual, registry.DeployDelayed, registry.DeployOnSuccess)
+		}
+		deployDelay, _ := flags.GetString("deploy-delay")
+		triggers, err := triggersFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		permissions, _ := flags.GetStringToString("permission")
+		timeout, _ := flags.GetInt("timeout-minutes")
+		concurrencyGroup, _ := flags.GetString("concurrency-group")
+		cancelInProgress, _ := flags.GetString("cancel-in-progress")
+
+		extractor, err := registry.FindExtractor(extractorName)
+		if err != nil {
+			return err
+		}
+		executor, err := registry.FindExecutor(executorName)
+		if err != nil {
+			return err
+		}
+
+		result, err := extractor.Extract(path)
+		if err != nil {
+			return fmt.Errorf("extraction error: %w", err)
+		}
+
+		output, err := executor.Generate(result, path, &registry.Options{
+			Name:     name,
+			OS:       osList,
+			FailFast: failFast,
+			Triggers: triggers,
+			Layout:   layout,
+
+			DeployWhen:  deployWhen,
+			DeployDelay: deployDelay,
+
+			Permissions:      permissions,
+			TimeoutMinutes:   timeout,
+			ConcurrencyGroup: concurrencyGroup,
+			CancelInProgress: cancelInProgress,
+		})
+		if err != nil {
+			return fmt.Errorf("generation error: %w", err)
+		}
+
+		fmt.Print(output)
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+		}
+		fmt.Fprint(os.Stderr, secretsChecklist(executors.RequiredSecrets(result)))
+		return nil
+	},
+}
+
+func init() {
+	flags := generateCmd.Flags()
+	flags.StringP("extractor", "x", "", "runtime extractor to use ("+strings.Join(registry.ExtractorNames(), ", ")+")")
+	flags.StringP("executor", "e", "GitHub", "CI/CD executor to use ("+strings.Join(registry.ExecutorNames(), ", ")+")")
+	flags.StringP("name", "n", "", "name of the generated workflow")
+	flags.StringSlice("os", nil, "runner operating systems to build on, more than one creates an OS matrix")
+	flags.Bool("fail-fast", false, "cancel the remaining matrix jobs as soon as one fails")
+	flags.String("layout", registry.LayoutSingle, "job layout: single runs everything in one job, split creates lint, test, build and deploy jobs")
+	flags.String("deploy-when", registry.DeployManual, "when deploy jobs start: manual, delayed or on_success")
+	flags.String("deploy-delay", "30 minutes", "how long a delayed deploy job waits")
+	flags.Bool("push", true, "run on pushes to the selected branches")
+	flags.StringSlice("branch", nil, "branches that trigger the workflow (default: the repository's default branch)")
+	flags.Bool("pull-request", false, "run on pull and merge requests")
+	flags.StringSlice("tag", nil, "tag patterns that trigger the workflow, e.g. v*")
+	flags.String("schedule", "", "cron schedule that triggers the workflow, e.g. \"0 3 * * 1\"")
+	flags.Bool("dispatch", false, "allow starting the workflow manually")
+	flags.StringSlice("input", nil, "manual dispatch input as name=description")
+	flags.StringSlice("paths", nil, "only run when files matching these patterns change")
+	flags.StringToString("permission", nil, "workflow token permissions as scope=level (default contents=read)")
+	flags.Int("timeout-minutes", 0, "job timeout in minutes (default 30)")
+	flags.String("concurrency-group", "", "concurrency group of the workflow (default per workflow and ref)")
+	flags.String("cancel-in-progress", "", "cancel superseded runs: true, false or an expression (default only for pull requests)")
+	generateCmd.MarkFlagRequired("extractor")
+
+	lockCmd.Flags().String("mirror", "", "directory holding git mirrors of the actions as <owner>/<repo>")
+	lockCmd.Flags().StringSlice("action", nil, "additional action references to lock, e.g. actions/cache@v4")
+	lockCmd.MarkFlagRequired("mirror")
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock [path]",
+	Short: "pin workflow actions",
+	Long:  "resolve the actions used by the project's workflows to commit SHAs from a local mirror and record them in " + actionlock.FileName,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+		mirror, _ := cmd.Flags().GetString("mirror")
+		actions, _ := cmd.Flags().GetStringSlice("action")
+
+		lock, err := actionlock.Load(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			lock = &actionlock.File{Actions: make(map[string]string)}
+		} else if err != nil {
+			return err
+		}
+
+		refs, err := actionlock.Scan(path)
+		if err != nil {
+			return fmt.Errorf("failed to scan workflows: %w", err)
+		}
+		for ref := range lock.Actions {
+			refs = append(refs, ref)
+		}
+		refs = append(refs, actions...)
+		slices.Sort(refs)
+		refs = slices.Compact(refs)
+
+		var failed []error
+		for _, ref := range refs {
+			sha, err := actionlock.Resolve(mirror, ref)
+			if err != nil {
+				failed = append(failed, err)
+				continue
+			}
+			lock.Actions[ref] = sha
+			fmt.Printf("%s -> %s\n", ref, sha)
+		}
+
+		if err := lock.Save(path); err != nil {
+			return err
+		}
+		return errors.Join(failed...)
+	},
+}
+
+func triggersFromFlags(cmd *cobra.Command) (registry.Triggers, error) {
+	flags := cmd.Flags()
+	push, _ := flags.GetBool("push")
+	branches, _ := flags.GetStringSlice("branch")
+	pullRequest, _ := flags.GetBool("pull-request")
+	tags, _ := flags.GetStringSlice("tag")
+	schedule, _ := flags.GetString("schedule")
+	dispatch, _ := flags.GetBool("dispatch")
+	inputs, _ := flags.GetStringSlice("input")
+	paths, _ := flags.GetStringSlice("paths")
+
+	triggers := registry.Triggers{
+		Push:        push,
+		Branches:    branches,
+		PullRequest: pullRequest,
+		Tags:        tags,
+		Schedule:    schedule,
+		Dispatch:    dispatch || len(inputs) > 0,
+		Paths:       paths,
+	}
+	var err error
+	triggers.Inputs, err = parseDispatchInputs(inputs)
+	return triggers, err
+}
+
+// parseDispatchInputs turns name=description pairs into dispatch inputs.
+func parseDispatchInputs(inputs []string) (map[string]string, error) {
+	var parsed map[string]string
+	for _, input := range inputs {
+		name, description, _ := strings.Cut(input, "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid input %q, expected name=description", input)
+		}
+		if parsed == nil {
+			parsed = make(map[string]string)
+		}
+		parsed[name] = description
+	}
+	return parsed, nil
+}
+
+// splitList splits a comma-separated field into its trimmed, non-empty
+// entries.
+func splitList(value string) []string {
+	var entries []string
+	for entry := range strings.SplitSeq(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
 func initialModel() Model {
 	dirti := textinput.New()
 	dirti.Placeholder = "./"
@@ -125,6 +352,21 @@ func initialModel() Model {
 	lanti.Focus()
 	lanti.CharLimit = 156
 	lanti.Width = 20
+
+	var fields []textinput.Model
+	for _, field := range []struct{ value, placeholder string }{
+		{"v*", "v*, release-*"},
+		{"0 3 * * 1", "0 3 * * 1"},
+		{"", "environment=Target environment, dry-run=Skip deploying"},
+		{"", "src/**, go.mod"},
+	} {
+		ti := textinput.New()
+		ti.Placeholder = field.placeholder
+		ti.SetValue(field.value)
+		ti.CharLimit = 156
+		ti.Width = 40
+		fields = append(fields, ti)
+	}
 	return Model{
 		screen: ScreenLanding,
 		landing: Landing{
@@ -135,6 +377,18 @@ func initialModel() Model {
 			Choices:  registry.ExecutorNames(),
 			Selected: -1,
 		},
+		triggers: Triggers{
+			Choices: []string{
+				"Push to the default branch",
+				"Pull requests",
+				"Tags",
+				"Schedule",
+				"Manual dispatch",
+			},
+			Enabled: []bool{true, false, false, false, false},
+			Labels:  []string{"Tag patterns", "Cron schedule", "Dispatch inputs", "Paths"},
+			Fields:  fields,
+		},
 		extractor: Extractor{
 			Choices:  registry.ExtractorNames(),
 			Selected: -1,
@@ -194,13 +448,51 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			case "enter", " ":
 				m.executor.Selected = m.executor.Cursor
-				m.screen = ScreenExtractor
+				m.screen = ScreenTriggers
 			case "shift+tab":
 				m.screen = ScreenLanding
 			}
 
 		}
 
+	case ScreenTriggers:
+		// On a text field, letters and spaces are typed into the field.
+		field := m.triggers.Cursor - len(m.triggers.Choices)
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			switch key := msg.String(); {
+			case key == "ctrl+c" || (key == "q" && field < 0):
+				return m, tea.Quit
+			case key == "up" || (key == "k" && field < 0):
+				if m.triggers.Cursor > 0 {
+					m.triggers.Cursor--
+				}
+			case key == "down" || (key == "j" && field < 0):
+				if m.triggers.Cursor < len(m.triggers.Choices)+len(m.triggers.Fields)-1 {
+					m.triggers.Cursor++
+				}
+			case key == " " && field < 0:
+				m.triggers.Enabled[m.triggers.Cursor] = !m.triggers.Enabled[m.triggers.Cursor]
+			case key == "enter":
+				m.screen = ScreenExtractor
+			case key == "shift+tab":
+				m.screen = ScreenExecutor
+			case field >= 0:
+				m.triggers.Fields[field], cmd = m.triggers.Fields[field].Update(msg)
+			}
+		default:
+			if field >= 0 {
+				m.triggers.Fields[field], cmd = m.triggers.Fields[field].Update(msg)
+			}
+		}
+		for i := range m.triggers.Fields {
+			if i == m.triggers.Cursor-len(m.triggers.Choices) {
+				m.triggers.Fields[i].Focus()
+			} else {
+				m.triggers.Fields[i].Blur()
+			}
+		}
+
 	case ScreenExtractor:
 		m.directory.Choices = m.directory.Choices[:0]
 		switch msg := msg.(type) {
@@ -226,7 +518,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 			case "shift+tab":
-				m.screen = ScreenExecutor
+				m.screen = ScreenTriggers
 			}
 		}
 
@@ -244,7 +536,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.directory.Value.Focus()
 			case "enter":
 				if m.directory.FocusInput {
-					m.output = GenerateWorkflow(m, m.directory.Value.Value())
+					m.output, m.secrets, m.warnings = GenerateWorkflow(m, m.directory.Value.Value())
 					m.screen = ScreenResult
 				}
 				if !m.directory.FocusInput && len(m.directory.Choices) > 0 {
@@ -252,7 +544,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					currentPath := m.directory.Value.Value()
 					selectedFolder := m.directory.Choices[m.directory.Selected]
 					m.directory.Value.SetValue(currentPath + "/" + selectedFolder)
-					m.output = GenerateWorkflow(m, m.directory.Value.Value())
+					m.output, m.secrets, m.warnings = GenerateWorkflow(m, m.directory.Value.Value())
 					m.screen = ScreenResult
 				}
 			case "up", "k":
@@ -317,7 +609,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func GenerateWorkflow(m Model, directory string) string {
+func GenerateWorkflow(m Model, directory string) (string, []registry.EnvVar, []string) {
 	extractor := registry.GetExtractor(m.extractor.Selected)
 	result, err := extractor.Extract(directory)
 	if err != nil {
@@ -326,13 +618,59 @@ func GenerateWorkflow(m Model, directory string) string {
 	}
 
 	executor := registry.GetExecutor(m.executor.Selected)
-	output, err := executor.Generate(result, directory, m.landing.Value.Value())
+	output, err := executor.Generate(result, directory, &registry.Options{
+		Name:     m.landing.Value.Value(),
+		Triggers: m.triggers.options(),
+	})
 	if err != nil {
 		fmt.Printf("Generation error: %v", err)
 		os.Exit(1)
 	}
 
-	return output
+	return output, executors.RequiredSecrets(result), result.Warnings
+}
+
+// secretsChecklist lists the secrets the user has to create before the
+// generated pipeline can run.
+func secretsChecklist(secrets []registry.EnvVar) string {
+	if len(secrets) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Secrets to create before the first run:\n")
+	for _, secret := range secrets {
+		fmt.Fprintf(&sb, "  [ ] %s (%s)\n", secret.Name, secret.Source)
+	}
+	return sb.String()
+}
+
+// options turns the toggles and fields into triggers. Dispatch inputs that
+// have no name are left out.
+func (t Triggers) options() registry.Triggers {
+	triggers := registry.Triggers{
+		Push:        t.Enabled[0],
+		PullRequest: t.Enabled[1],
+		Dispatch:    t.Enabled[4],
+		Paths:       splitList(t.Fields[3].Value()),
+	}
+	if t.Enabled[2] {
+		triggers.Tags = splitList(t.Fields[0].Value())
+	}
+	if t.Enabled[3] {
+		triggers.Schedule = strings.TrimSpace(t.Fields[1].Value())
+	}
+	if t.Enabled[4] {
+		for _, input := range splitList(t.Fields[2].Value()) {
+			if name, description, _ := strings.Cut(input, "="); name != "" {
+				if triggers.Inputs == nil {
+					triggers.Inputs = make(map[string]string)
+				}
+				triggers.Inputs[name] = description
+			}
+		}
+	}
+	return triggers
 }
 
 func (m Model) View() string {
@@ -380,6 +718,36 @@ func (m Model) View() string {
 		}
 		sb.WriteString("\nPress q to quit.\n")
 
+	case ScreenTriggers:
+		sb.WriteString(titleStyle.Render("When Should The Workflow Run?"))
+		sb.WriteString("\n\n")
+		for i, choice := range m.triggers.Choices {
+			cursor := " "
+			if m.triggers.Cursor == i {
+				cursor = ">"
+			}
+			checked := " "
+			if m.triggers.Enabled[i] {
+				checked = "x"
+			}
+			s := fmt.Sprintf("%s [%s] %s", cursor, checked, choice)
+			if m.triggers.Cursor == i {
+				sb.WriteString(selectedStyle.Render(s))
+			} else {
+				sb.WriteString(normalStyle.Render(s))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+		for i, field := range m.triggers.Fields {
+			cursor := " "
+			if m.triggers.Cursor == len(m.triggers.Choices)+i {
+				cursor = ">"
+			}
+			fmt.Fprintf(&sb, "%s %-16s %s\n", cursor, m.triggers.Labels[i]+":", field.View())
+		}
+		sb.WriteString("\nPress space to toggle, up and down to move, enter to continue.\n")
+
 	case ScreenExtractor:
 		sb.WriteString(titleStyle.Render("What Extractor Would You Like To Use?"))
 		sb.WriteString("\n\n")
@@ -413,6 +781,12 @@ func (m Model) View() string {
 		sb.WriteString(titleStyle.Render("Workflow Overview:") + "\n\n")
 		fmt.Fprintf(&sb, "%s\n\n", m.directory.Value.Value())
 		sb.WriteString(outputStyle.Render(m.output))
+		for _, warning := range m.warnings {
+			sb.WriteString("\n\n" + normalStyle.Render("warning: "+warning))
+		}
+		if checklist := secretsChecklist(m.secrets); checklist != "" {
+			sb.WriteString("\n\n" + normalStyle.Render(checklist))
+		}
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, sb.String())
 	}
 
@@ -422,6 +796,8 @@ func (m Model) View() string {
 
 func main() {
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(lockCmd)
 	if err := fang.Execute(context.Background(), rootCmd); err != nil {
 		fmt.Println("An Error Ocurred")
 		os.Exit(1)
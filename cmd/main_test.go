@@ -0,0 +1,70 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func press(m Model, keys ...string) Model {
+	for _, key := range keys {
+		var msg tea.KeyMsg
+		switch key {
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		}
+		next, _ := m.Update(msg)
+		m = next.(Model)
+	}
+	return m
+}
+
+func TestTriggersScreen(t *testing.T) {
+	m := initialModel()
+	m.screen = ScreenTriggers
+
+	// Enable tags, schedule and dispatch, then fill in the fields. Letters
+	// that navigate the toggles are typed into the fields.
+	m = press(m, "down", "down", " ", "down", " ", "down", " ")
+	m = press(m, "down", ",", " ", "r", "e", "l", "-", "*")
+	m = press(m, "down", "backspace", "4")
+	m = press(m, "down", "e", "n", "v", "=", "T", "a", "r", "g", "e", "t", ",", "=", "x", ",", "q", "=")
+	m = press(m, "down", "s", "r", "c", "/", "*", "*", ",", "j", "k")
+
+	if m.screen != ScreenTriggers {
+		t.Fatalf("screen = %q, want the triggers screen", m.screen)
+	}
+	triggers := m.triggers.options()
+	if !triggers.Push || triggers.PullRequest || !triggers.Dispatch {
+		t.Errorf("toggles = push %v, pull request %v, dispatch %v", triggers.Push, triggers.PullRequest, triggers.Dispatch)
+	}
+	if want := []string{"v*", "rel-*"}; !slices.Equal(triggers.Tags, want) {
+		t.Errorf("Tags = %q, want %q", triggers.Tags, want)
+	}
+	if want := "0 3 * * 4"; triggers.Schedule != want {
+		t.Errorf("Schedule = %q, want %q", triggers.Schedule, want)
+	}
+	if want := map[string]string{"env": "Target", "q": ""}; !maps.Equal(triggers.Inputs, want) {
+		t.Errorf("Inputs = %q, want %q", triggers.Inputs, want)
+	}
+	if want := []string{"src/**", "jk"}; !slices.Equal(triggers.Paths, want) {
+		t.Errorf("Paths = %q, want %q", triggers.Paths, want)
+	}
+}
+
+func TestTriggersScreenDisabled(t *testing.T) {
+	triggers := initialModel().triggers.options()
+	if len(triggers.Tags) > 0 || triggers.Schedule != "" || triggers.Dispatch || len(triggers.Inputs) > 0 || len(triggers.Paths) > 0 {
+		t.Errorf("options() = %+v, want only pushes", triggers)
+	}
+}
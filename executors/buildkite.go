@@ -0,0 +1,106 @@
+package executors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	registry.RegisterExecutor(&BuildkiteExecutor{})
+}
+
+const buildkiteDockerPlugin = "docker#v5.12.0"
+
+type BuildkiteExecutor struct{}
+
+type buildkitePipeline struct {
+	Steps []any `yaml:"steps"`
+}
+
+type buildkiteStep struct {
+	Label         string                       `yaml:"label"`
+	Key           string                       `yaml:"key"`
+	Commands      []string                     `yaml:"commands"`
+	Plugins       []map[string]buildkitePlugin `yaml:"plugins,omitempty"`
+	ArtifactPaths []string                     `yaml:"artifact_paths,omitempty"`
+	DependsOn     []string                     `yaml:"depends_on,omitempty"`
+}
+
+type buildkitePlugin struct {
+	Image string `yaml:"image"`
+}
+
+func (b *BuildkiteExecutor) Name() string {
+	return "Buildkite"
+}
+
+func (b *BuildkiteExecutor) Generate(result *registry.ExtractorResult, path string, opts *registry.Options) (string, error) {
+	phases := make(map[string][]string)
+	for _, key := range orderedScripts(result.Scripts) {
+		phase := scriptPhase(key)
+		if phase == "install" {
+			continue
+		}
+		phases[phase] = append(phases[phase], key)
+	}
+
+	var steps []any
+	var previous []string
+	for _, phase := range lifecyclePhases {
+		keys := phases[phase]
+		if len(keys) == 0 {
+			continue
+		}
+		if len(previous) > 0 {
+			steps = append(steps, "wait")
+		}
+
+		var current []string
+		for _, key := range keys {
+			commands := append(installCommands(result), result.Scripts[key])
+
+			step := buildkiteStep{
+				Label:     key,
+				Key:       strings.ToLower(key),
+				Commands:  commands,
+				DependsOn: previous,
+			}
+			if result.Image != "" {
+				step.Plugins = []map[string]buildkitePlugin{
+					{buildkiteDockerPlugin: {Image: result.Image}},
+				}
+			}
+			if phase == "build" {
+				for _, artifact := range result.Artifacts {
+					step.ArtifactPaths = append(step.ArtifactPaths, artifactGlob(artifact))
+				}
+			}
+
+			steps = append(steps, step)
+			current = append(current, step.Key)
+		}
+		previous = current
+	}
+
+	pipeline := buildkitePipeline{Steps: steps}
+	data, err := yaml.Marshal(&pipeline)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal pipeline: %w", err)
+	}
+
+	pipelineDir := filepath.Join(path, ".buildkite")
+	if err := os.MkdirAll(pipelineDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create pipeline directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(pipelineDir, "pipeline.yml"), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write pipeline file: %w", err)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,62 @@
+package executors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	registry.RegisterExecutor(&CloudBuildExecutor{})
+}
+
+type CloudBuildExecutor struct{}
+
+type cloudbuildConfig struct {
+	Steps []cloudbuildStep `yaml:"steps"`
+}
+
+type cloudbuildStep struct {
+	ID         string   `yaml:"id"`
+	Name       string   `yaml:"name"`
+	Entrypoint string   `yaml:"entrypoint"`
+	Args       []string `yaml:"args"`
+}
+
+func (c *CloudBuildExecutor) Name() string {
+	return "Google Cloud Build"
+}
+
+func (c *CloudBuildExecutor) Generate(result *registry.ExtractorResult, path string, opts *registry.Options) (string, error) {
+	var steps []cloudbuildStep
+	for _, key := range orderedScripts(result.Scripts) {
+		if key == "Install" {
+			continue
+		}
+
+		commands := append(installCommands(result), result.Scripts[key])
+
+		steps = append(steps, cloudbuildStep{
+			ID:         key,
+			Name:       result.Image,
+			Entrypoint: "sh",
+			Args:       []string{"-c", strings.Join(commands, " && ")},
+		})
+	}
+
+	config := cloudbuildConfig{Steps: steps}
+	data, err := yaml.Marshal(&config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal build config: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "cloudbuild.yaml"), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write build config file: %w", err)
+	}
+
+	return string(data), nil
+}
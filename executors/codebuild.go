@@ -0,0 +1,122 @@
+package executors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	registry.RegisterExecutor(&CodeBuildExecutor{})
+}
+
+type CodeBuildExecutor struct{}
+
+type codebuildSpec struct {
+	Version   float64             `yaml:"version"`
+	Phases    codebuildPhases     `yaml:"phases"`
+	Artifacts *codebuildArtifacts `yaml:"artifacts,omitempty"`
+}
+
+type codebuildPhases struct {
+	Install   *codebuildPhase `yaml:"install,omitempty"`
+	PreBuild  *codebuildPhase `yaml:"pre_build,omitempty"`
+	Build     *codebuildPhase `yaml:"build,omitempty"`
+	PostBuild *codebuildPhase `yaml:"post_build,omitempty"`
+}
+
+type codebuildPhase struct {
+	OnFailure       string            `yaml:"on-failure,omitempty"`
+	RuntimeVersions map[string]string `yaml:"runtime-versions,omitempty"`
+	Commands        []string          `yaml:"commands,omitempty"`
+}
+
+type codebuildArtifacts struct {
+	Files []string `yaml:"files"`
+}
+
+func (c *CodeBuildExecutor) Name() string {
+	return "AWS CodeBuild"
+}
+
+func (c *CodeBuildExecutor) Generate(result *registry.ExtractorResult, path string, opts *registry.Options) (string, error) {
+	install := &codebuildPhase{OnFailure: "ABORT"}
+	preBuild := &codebuildPhase{OnFailure: "ABORT"}
+	build := &codebuildPhase{OnFailure: "ABORT"}
+	postBuild := &codebuildPhase{}
+
+	if runtime, version := codebuildRuntime(result); runtime != "" {
+		install.RuntimeVersions = map[string]string{runtime: version}
+	}
+
+	for _, key := range orderedScripts(result.Scripts) {
+		command := result.Scripts[key]
+		switch scriptPhase(key) {
+		case "install":
+			install.Commands = append(install.Commands, installCommands(result)...)
+		case "build":
+			build.Commands = append(build.Commands, command)
+		case "deploy":
+			postBuild.Commands = append(postBuild.Commands, command)
+		default:
+			preBuild.Commands = append(preBuild.Commands, command)
+		}
+	}
+
+	spec := codebuildSpec{Version: 0.2}
+	if len(install.Commands) > 0 || len(install.RuntimeVersions) > 0 {
+		spec.Phases.Install = install
+	}
+	if len(preBuild.Commands) > 0 {
+		spec.Phases.PreBuild = preBuild
+	}
+	if len(build.Commands) > 0 {
+		spec.Phases.Build = build
+	}
+	if len(postBuild.Commands) > 0 {
+		spec.Phases.PostBuild = postBuild
+	}
+
+	if len(result.Artifacts) > 0 {
+		spec.Artifacts = &codebuildArtifacts{}
+		for _, artifact := range result.Artifacts {
+			spec.Artifacts.Files = append(spec.Artifacts.Files, artifactGlob(artifact))
+		}
+	}
+
+	data, err := yaml.Marshal(&spec)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal buildspec: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "buildspec.yml"), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write buildspec file: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// codebuildRuntime maps the detected runtime onto the names and version
+// granularity accepted by CodeBuild's runtime-versions.
+func codebuildRuntime(result *registry.ExtractorResult) (string, string) {
+	parts := strings.Split(result.RuntimeVersion, ".")
+	switch result.Runtime {
+	case "node":
+		return "nodejs", parts[0]
+	case "python":
+		if len(parts) > 1 {
+			return "python", parts[0] + "." + parts[1]
+		}
+		return "python", parts[0]
+	case "go":
+		if len(parts) > 1 {
+			return "golang", parts[0] + "." + parts[1]
+		}
+		return "golang", parts[0]
+	}
+	return "", ""
+}
@@ -0,0 +1,251 @@
+package executors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+func init() {
+	registry.RegisterExecutor(&DockerfileExecutor{})
+}
+
+type DockerfileExecutor struct{}
+
+var dockerignoreCommon = []string{".git", ".github", ".dockerignore", "Dockerfile"}
+
+var dockerignoreRuntime = map[string][]string{
+	"go":     {"bin/"},
+	"node":   {"node_modules", "dist", "npm-debug.log*"},
+	"python": {".venv", "__pycache__", "*.pyc", ".pytest_cache", "dist"},
+}
+
+func (d *DockerfileExecutor) Name() string {
+	return "Dockerfile"
+}
+
+func (d *DockerfileExecutor) Generate(result *registry.ExtractorResult, path string, opts *registry.Options) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# syntax=docker/dockerfile:1\n\n")
+
+	switch result.Runtime {
+	case "go":
+		writeGoDockerfile(&sb, result, path)
+	case "node":
+		writeNodeDockerfile(&sb, result)
+	case "python":
+		if result.PackageManager == "conda" {
+			writeCondaDockerfile(&sb, result, path)
+		} else {
+			writePythonDockerfile(&sb, result, path)
+		}
+	default:
+		return "", fmt.Errorf("unsupported runtime for Dockerfile: %q", result.Runtime)
+	}
+
+	dockerfile := sb.String()
+	if err := os.WriteFile(filepath.Join(path, "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+		return "", fmt.Errorf("failed to write Dockerfile: %w", err)
+	}
+
+	ignore := append(slices.Clone(dockerignoreCommon), dockerignoreRuntime[result.Runtime]...)
+	if err := os.WriteFile(filepath.Join(path, ".dockerignore"), []byte(strings.Join(ignore, "\n")+"\n"), 0644); err != nil {
+		return "", fmt.Errorf("failed to write .dockerignore: %w", err)
+	}
+
+	return dockerfile, nil
+}
+
+// writeGoDockerfile builds a static binary and ships it on a distroless base.
+func writeGoDockerfile(sb *strings.Builder, result *registry.ExtractorResult, path string) {
+	fmt.Fprintf(sb, "FROM %s AS deps\n", result.Image)
+	sb.WriteString("WORKDIR /src\n")
+	fmt.Fprintf(sb, "COPY %s ./\n", strings.Join(dependencyFiles("go.mod", result.Lockfile), " "))
+	sb.WriteString("RUN go mod download\n\n")
+
+	sb.WriteString("FROM deps AS build\n")
+	sb.WriteString("ENV CGO_ENABLED=0\n")
+	sb.WriteString("COPY . .\n")
+	fmt.Fprintf(sb, "RUN %s\n\n", result.Scripts["Build"])
+
+	binaries := registry.GoBinaries(path)
+	if len(binaries) == 0 {
+		sb.WriteString("# No main package found, so there is no binary to ship.\n")
+		return
+	}
+
+	sb.WriteString("FROM gcr.io/distroless/static-debian12 AS runtime\n")
+	sb.WriteString("COPY --from=build /src/bin/ /usr/local/bin/\n")
+	sb.WriteString("USER nonroot:nonroot\n")
+
+	switch len(binaries) {
+	case 1:
+		fmt.Fprintf(sb, "ENTRYPOINT [\"/usr/local/bin/%s\"]\n", binaries[0])
+	default:
+		fmt.Fprintf(sb, "# Several binaries are built (%s); pick the one to run.\n", strings.Join(binaries, ", "))
+		fmt.Fprintf(sb, "ENTRYPOINT [\"/usr/local/bin/%s\"]\n", binaries[0])
+	}
+}
+
+// writeNodeDockerfile installs dependencies from the lockfile, runs the build
+// script and starts the app on an alpine image with production dependencies
+// only.
+func writeNodeDockerfile(sb *strings.Builder, result *registry.ExtractorResult) {
+	writeNodeInstallStage(sb, result, "deps", result.Scripts["Install"])
+	writeNodeInstallStage(sb, result, "prod-deps", nodeProductionInstall(result))
+
+	sb.WriteString("FROM deps AS build\n")
+	sb.WriteString("COPY . .\n")
+	if build := result.Scripts["Build"]; build != "" {
+		fmt.Fprintf(sb, "RUN %s\n", build)
+	}
+	if len(result.Artifacts) == 0 {
+		sb.WriteString("RUN rm -rf node_modules\n")
+	}
+	sb.WriteString("\n")
+
+	fmt.Fprintf(sb, "FROM %s AS runtime\n", result.Image)
+	sb.WriteString("WORKDIR /app\n")
+	sb.WriteString("ENV NODE_ENV=production\n")
+	if len(result.Artifacts) > 0 {
+		sb.WriteString("COPY --from=build /app/package.json ./\n")
+		for _, artifact := range result.Artifacts {
+			fmt.Fprintf(sb, "COPY --from=build /app/%s ./%s\n", artifact, artifact)
+		}
+	} else {
+		sb.WriteString("COPY --from=build /app ./\n")
+	}
+	sb.WriteString("COPY --from=prod-deps /app/node_modules ./node_modules\n")
+	sb.WriteString("CMD [\"npm\", \"start\"]\n")
+}
+
+func writeNodeInstallStage(sb *strings.Builder, result *registry.ExtractorResult, stage, install string) {
+	fmt.Fprintf(sb, "FROM %s AS %s\n", result.Image, stage)
+	sb.WriteString("WORKDIR /app\n")
+	for _, command := range packageManagerSetup(result) {
+		fmt.Fprintf(sb, "RUN %s\n", command)
+	}
+	fmt.Fprintf(sb, "COPY %s ./\n", strings.Join(dependencyFiles("package.json", result.Lockfile), " "))
+	fmt.Fprintf(sb, "RUN %s\n\n", install)
+}
+
+// nodeProductionInstall returns the Install script without devDependencies.
+// Yarn 2+ dropped --production in favor of the workspaces focus command.
+func nodeProductionInstall(result *registry.ExtractorResult) string {
+	install := result.Scripts["Install"]
+	switch result.PackageManager {
+	case "pnpm":
+		return install + " --prod"
+	case "yarn":
+		version := result.PackageManagerVersion
+		if strings.Contains(install, "--immutable") || (version != "" && !strings.HasPrefix(version, "1.")) {
+			return "yarn workspaces focus --all --production"
+		}
+		return install + " --production"
+	case "bun":
+		return install + " --production"
+	}
+	return install + " --omit=dev"
+}
+
+// writePythonDockerfile installs dependencies into a virtualenv that is
+// copied into a slim runtime image.
+func writePythonDockerfile(sb *strings.Builder, result *registry.ExtractorResult, path string) {
+	fmt.Fprintf(sb, "FROM %s AS deps\n", result.Image)
+	sb.WriteString("WORKDIR /app\n")
+	sb.WriteString("ENV VIRTUAL_ENV=/opt/venv PATH=/opt/venv/bin:$PATH\n")
+	sb.WriteString("RUN python -m venv /opt/venv\n")
+
+	installProject := false
+	switch result.PackageManager {
+	case "uv":
+		sb.WriteString("COPY --from=ghcr.io/astral-sh/uv:latest /uv /usr/local/bin/uv\n")
+		sb.WriteString("ENV UV_PROJECT_ENVIRONMENT=/opt/venv\n")
+		fmt.Fprintf(sb, "COPY %s ./\n", strings.Join(dependencyFiles("pyproject.toml", result.Lockfile), " "))
+		sb.WriteString("RUN uv sync --no-dev --no-install-project")
+		if result.Lockfile != "" {
+			sb.WriteString(" --frozen")
+		}
+		sb.WriteString("\n\n")
+	case "poetry":
+		sb.WriteString("RUN pip install --no-cache-dir poetry\n")
+		fmt.Fprintf(sb, "COPY %s ./\n", strings.Join(dependencyFiles("pyproject.toml", result.Lockfile), " "))
+		sb.WriteString("RUN poetry install --no-root --only main\n\n")
+	case "pdm":
+		sb.WriteString("RUN pip install --no-cache-dir pdm\n")
+		fmt.Fprintf(sb, "COPY %s ./\n", strings.Join(dependencyFiles("pyproject.toml", result.Lockfile), " "))
+		if result.Lockfile != "" {
+			sb.WriteString("RUN pdm sync --prod --no-self\n\n")
+		} else {
+			sb.WriteString("RUN pdm install --prod --no-self\n\n")
+		}
+	case "pipenv":
+		sb.WriteString("RUN pip install --no-cache-dir pipenv\n")
+		fmt.Fprintf(sb, "COPY %s ./\n", strings.Join(dependencyFiles("Pipfile", result.Lockfile), " "))
+		if result.Lockfile != "" {
+			sb.WriteString("RUN pipenv install --system --deploy\n\n")
+		} else {
+			sb.WriteString("RUN pipenv install --system --skip-lock\n\n")
+		}
+	default:
+		if result.Lockfile != "" {
+			fmt.Fprintf(sb, "COPY %s ./\n", result.Lockfile)
+			fmt.Fprintf(sb, "RUN pip install --no-cache-dir -r %s\n\n", result.Lockfile)
+		} else {
+			installProject = registry.FirstExisting(path, "pyproject.toml", "setup.py") != ""
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("FROM deps AS build\n")
+	sb.WriteString("COPY . .\n")
+	if installProject {
+		sb.WriteString("RUN pip install --no-cache-dir .\n")
+	}
+	if build := result.Scripts["Build"]; build != "" {
+		fmt.Fprintf(sb, "RUN %s\n", build)
+	}
+	sb.WriteString("\n")
+
+	fmt.Fprintf(sb, "FROM %s AS runtime\n", result.Image)
+	sb.WriteString("WORKDIR /app\n")
+	sb.WriteString("ENV VIRTUAL_ENV=/opt/venv PATH=/opt/venv/bin:$PATH\n")
+	sb.WriteString("COPY --from=build /opt/venv /opt/venv\n")
+	sb.WriteString("COPY --from=build /app /app\n")
+	writePythonCmd(sb, path)
+}
+
+// writeCondaDockerfile creates the conda environment under a fixed prefix,
+// so the runtime stage can copy it and put it first on the PATH.
+func writeCondaDockerfile(sb *strings.Builder, result *registry.ExtractorResult, path string) {
+	fmt.Fprintf(sb, "FROM %s AS deps\n", result.Image)
+	fmt.Fprintf(sb, "COPY %s /tmp/\n", result.Lockfile)
+	fmt.Fprintf(sb, "RUN conda env create --file /tmp/%s --prefix /opt/env && conda clean --all --yes\n\n", result.Lockfile)
+
+	fmt.Fprintf(sb, "FROM %s AS runtime\n", result.Image)
+	sb.WriteString("WORKDIR /app\n")
+	sb.WriteString("ENV PATH=/opt/env/bin:$PATH\n")
+	sb.WriteString("COPY --from=deps /opt/env /opt/env\n")
+	sb.WriteString("COPY . .\n")
+	writePythonCmd(sb, path)
+}
+
+func writePythonCmd(sb *strings.Builder, path string) {
+	if entry := registry.FirstExisting(path, "main.py", "app.py"); entry != "" {
+		fmt.Fprintf(sb, "CMD [\"python\", \"%s\"]\n", entry)
+	} else {
+		sb.WriteString("# No entry module found; set the CMD to start your application.\n")
+	}
+}
+
+func dependencyFiles(manifest, lockfile string) []string {
+	files := []string{manifest}
+	if lockfile != "" && lockfile != manifest {
+		files = append(files, lockfile)
+	}
+	return files
+}
@@ -0,0 +1,59 @@
+package executors
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+func init() {
+	registry.RegisterExecutor(&GiteaExecutor{name: "Gitea", dir: ".gitea", runsOn: "ubuntu-latest"})
+	registry.RegisterExecutor(&GiteaExecutor{name: "Forgejo", dir: ".forgejo", runsOn: "docker"})
+}
+
+// GiteaExecutor writes GitHub-compatible workflows for Gitea and Forgejo
+// Actions, which read from their own workflow directory, use different
+// runner labels and resolve short action names against their own instance.
+type GiteaExecutor struct {
+	name   string
+	dir    string
+	runsOn string
+}
+
+func (g *GiteaExecutor) Name() string {
+	return g.name
+}
+
+func (g *GiteaExecutor) Generate(result *registry.ExtractorResult, path string, opts *registry.Options) (string, error) {
+	// Self-hosted runners rarely offer the GitHub-hosted OS images, so the
+	// OS matrix is dropped and every job runs on the platform's default label.
+	giteaOpts := *opts
+	giteaOpts.OS = nil
+
+	workflow := (&GithubExecutor{}).buildWorkflow(result, path, &giteaOpts)
+	versions, err := pinGithubActions(&workflow, path)
+	if err != nil {
+		return "", err
+	}
+	for id, job := range workflow.Jobs {
+		job.RunsOn = g.runsOn
+		for i := range job.Steps {
+			job.Steps[i].Uses = qualifyAction(job.Steps[i].Uses)
+		}
+		workflow.Jobs[id] = job
+	}
+	qualified := make(map[string]string, len(versions))
+	for uses, version := range versions {
+		qualified[qualifyAction(uses)] = version
+	}
+
+	return writeGithubWorkflow(&workflow, qualified, filepath.Join(path, g.dir, "workflows"), opts.Name)
+}
+
+func qualifyAction(uses string) string {
+	if uses == "" || strings.HasPrefix(uses, "./") || strings.Contains(uses, "://") {
+		return uses
+	}
+	return "https://github.com/" + uses
+}
@@ -2,9 +2,12 @@ package executors
 
 import (
 	"fmt"
-	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/zraisan/AutoFlow/registry"
 	"gopkg.in/yaml.v3"
@@ -16,79 +19,501 @@ func init() {
 
 type GithubExecutor struct{}
 
+const (
+	githubDefaultTimeoutMinutes   = 30
+	githubDefaultConcurrencyGroup = "${{ github.workflow }}-${{ github.ref }}"
+	// Superseded runs are only cancelled for pull requests, so every push to
+	// a branch still gets a complete run.
+	githubDefaultCancelInProgress = "${{ startsWith(github.ref, 'refs/pull/') }}"
+)
+
+var githubDefaultPermissions = map[string]string{
+	"contents": "read",
+}
+
 type githubWorkflow struct {
-	Name string               `yaml:"name"`
-	On   githubOn             `yaml:"on"`
-	Jobs map[string]githubJob `yaml:"jobs"`
+	Name        string               `yaml:"name"`
+	On          githubOn             `yaml:"on"`
+	Permissions map[string]string    `yaml:"permissions,omitempty"`
+	Concurrency *githubConcurrency   `yaml:"concurrency,omitempty"`
+	Env         map[string]string    `yaml:"env,omitempty"`
+	Jobs        map[string]githubJob `yaml:"jobs"`
+}
+
+type githubConcurrency struct {
+	Group            string `yaml:"group"`
+	CancelInProgress any    `yaml:"cancel-in-progress"`
 }
 
 type githubOn struct {
-	Push        *githubPushEvent        `yaml:"push,omitempty"`
-	PullRequest *githubPullRequestEvent `yaml:"pull_request,omitempty"`
+	Push             *githubPushEvent        `yaml:"push,omitempty"`
+	PullRequest      *githubPullRequestEvent `yaml:"pull_request,omitempty"`
+	Schedule         []githubSchedule        `yaml:"schedule,omitempty"`
+	WorkflowDispatch *githubDispatchEvent    `yaml:"workflow_dispatch,omitempty"`
 }
 
 type githubPushEvent struct {
 	Branches []string `yaml:"branches,omitempty"`
+	Tags     []string `yaml:"tags,omitempty"`
+	Paths    []string `yaml:"paths,omitempty"`
 }
 
 type githubPullRequestEvent struct {
 	Branches []string `yaml:"branches,omitempty"`
+	Paths    []string `yaml:"paths,omitempty"`
+}
+
+type githubSchedule struct {
+	Cron string `yaml:"cron"`
+}
+
+type githubDispatchEvent struct {
+	Inputs map[string]githubDispatchInput `yaml:"inputs,omitempty"`
+}
+
+type githubDispatchInput struct {
+	Description string `yaml:"description,omitempty"`
+	Required    bool   `yaml:"required"`
+	Type        string `yaml:"type"`
 }
 
 type githubJob struct {
-	RunsOn string       `yaml:"runs-on"`
-	Steps  []githubStep `yaml:"steps"`
+	Needs          []string                 `yaml:"needs,omitempty"`
+	If             string                   `yaml:"if,omitempty"`
+	RunsOn         string                   `yaml:"runs-on"`
+	TimeoutMinutes int                      `yaml:"timeout-minutes,omitempty"`
+	Strategy       *githubStrategy          `yaml:"strategy,omitempty"`
+	Services       map[string]githubService `yaml:"services,omitempty"`
+	Env            map[string]string        `yaml:"env,omitempty"`
+	Defaults       *githubDefaults          `yaml:"defaults,omitempty"`
+	Steps          []githubStep             `yaml:"steps"`
+}
+
+type githubDefaults struct {
+	Run githubRunDefaults `yaml:"run"`
+}
+
+type githubRunDefaults struct {
+	Shell string `yaml:"shell"`
+}
+
+type githubService struct {
+	Image   string            `yaml:"image"`
+	Env     map[string]string `yaml:"env,omitempty"`
+	Ports   []string          `yaml:"ports,omitempty"`
+	Options string            `yaml:"options,omitempty"`
+}
+
+type githubStrategy struct {
+	FailFast bool                `yaml:"fail-fast"`
+	Matrix   map[string][]string `yaml:"matrix"`
 }
 
 type githubStep struct {
 	Name string            `yaml:"name,omitempty"`
+	If   string            `yaml:"if,omitempty"`
 	Uses string            `yaml:"uses,omitempty"`
 	Run  string            `yaml:"run,omitempty"`
 	With map[string]string `yaml:"with,omitempty"`
+	Env  map[string]string `yaml:"env,omitempty"`
 }
 
 func (g *GithubExecutor) Name() string {
 	return "GitHub"
 }
 
-func (g *GithubExecutor) Generate(result *registry.ExtractorResult, path, name string) (string, error) {
-	steps := []githubStep{
+func (g *GithubExecutor) Generate(result *registry.ExtractorResult, path string, opts *registry.Options) (string, error) {
+	workflow := g.buildWorkflow(result, path, opts)
+	versions, err := pinGithubActions(&workflow, path)
+	if err != nil {
+		return "", err
+	}
+	return writeGithubWorkflow(&workflow, versions, filepath.Join(path, ".github/workflows"), opts.Name)
+}
+
+func (g *GithubExecutor) buildWorkflow(result *registry.ExtractorResult, path string, opts *registry.Options) githubWorkflow {
+	workflow := githubWorkflow{
+		Name:        opts.Name,
+		On:          githubTriggers(opts.Triggers, path),
+		Permissions: githubDefaultPermissions,
+		Concurrency: &githubConcurrency{
+			Group:            githubDefaultConcurrencyGroup,
+			CancelInProgress: githubDefaultCancelInProgress,
+		},
+	}
+	if len(opts.Permissions) > 0 {
+		workflow.Permissions = opts.Permissions
+	}
+	if opts.ConcurrencyGroup != "" {
+		workflow.Concurrency.Group = opts.ConcurrencyGroup
+	}
+	if opts.CancelInProgress != "" {
+		workflow.Concurrency.CancelInProgress = opts.CancelInProgress
+		if cancel, err := strconv.ParseBool(opts.CancelInProgress); err == nil {
+			workflow.Concurrency.CancelInProgress = cancel
+		}
+	}
+
+	// The go directive leg would otherwise switch to the toolchain version
+	// on its own and run the same Go as the toolchain leg.
+	if result.Runtime == "go" && len(result.RuntimeVersions) > 1 {
+		workflow.Env = map[string]string{"GOTOOLCHAIN": "local"}
+	}
+
+	if opts.Layout == registry.LayoutSplit {
+		workflow.Jobs = g.createSplitJobs(result, opts)
+		return workflow
+	}
+
+	serviceOpts, services := githubServiceRunners(opts, result.Services)
+	job := g.createJob(result, serviceOpts, true)
+	job.Services = services
+	job.Env = githubSecretsEnv(result)
+	job.Steps = append(job.Steps, g.createLintSteps(result)...)
+	for _, step := range githubScriptSteps(result, orderedScripts(result.Scripts)) {
+		if scriptPhase(step.Name) == "deploy" {
+			step.If = githubConditions(githubDeployCondition(opts.Triggers), githubPrimaryLeg(job, result))
+		}
+		job.Steps = append(job.Steps, step)
+	}
+	workflow.Jobs = map[string]githubJob{
+		"build": job,
+	}
+	return workflow
+}
+
+// createSplitJobs spreads the scripts over lint, test, build and deploy jobs.
+// Lint and test run in parallel, build waits for both and deploy receives
+// the build output as an artifact.
+func (g *GithubExecutor) createSplitJobs(result *registry.ExtractorResult, opts *registry.Options) map[string]githubJob {
+	phases := make(map[string][]string)
+	for _, key := range orderedScripts(result.Scripts) {
+		phase := scriptPhase(key)
+		phases[phase] = append(phases[phase], key)
+	}
+
+	jobs := make(map[string]githubJob)
+	var checks []string
+
+	lintSteps := g.createLintSteps(result)
+	if len(lintSteps) > 0 || len(phases["lint"]) > 0 {
+		job := g.createJob(result, opts, false)
+		job.Steps = append(job.Steps, lintSteps...)
+		job.Steps = append(job.Steps, githubScriptSteps(result, phases["lint"])...)
+		jobs["lint"] = job
+		checks = append(checks, "lint")
+	}
+
+	if len(phases["test"]) > 0 {
+		serviceOpts, services := githubServiceRunners(opts, result.Services)
+		job := g.createJob(result, serviceOpts, true)
+		job.Services = services
+		job.Env = githubSecretsEnv(result)
+		job.Steps = append(job.Steps, githubScriptSteps(result, phases["test"])...)
+		jobs["test"] = job
+		checks = append(checks, "test")
+	}
+
+	handoff := len(phases["build"]) > 0 && len(phases["deploy"]) > 0 && len(result.Artifacts) > 0
+	previous := checks
+	if len(phases["build"]) > 0 {
+		// Without a test job the build job runs the matrix, so the OS
+		// matrix still applies.
+		job := g.createJob(result, opts, len(phases["test"]) == 0)
+		job.Needs = checks
+		job.Env = githubSecretsEnv(result)
+		job.Steps = append(job.Steps, githubScriptSteps(result, phases["build"])...)
+		if handoff {
+			job.Steps = append(job.Steps, githubStep{
+				Name: "Upload build output",
+				If:   githubPrimaryLeg(job, result),
+				Uses: "actions/upload-artifact@v4",
+				With: map[string]string{
+					"name": "build-output",
+					"path": strings.Join(result.Artifacts, "\n"),
+				},
+			})
+		}
+		jobs["build"] = job
+		previous = []string{"build"}
+	}
+
+	if len(phases["deploy"]) > 0 {
+		job := g.createJob(result, opts, false)
+		job.Needs = previous
+		job.If = githubDeployCondition(opts.Triggers)
+		job.Env = githubSecretsEnv(result)
+		if handoff {
+			downloadPath := "."
+			if len(result.Artifacts) == 1 {
+				downloadPath = result.Artifacts[0]
+			}
+			job.Steps = append(job.Steps, githubStep{
+				Name: "Download build output",
+				Uses: "actions/download-artifact@v4",
+				With: map[string]string{
+					"name": "build-output",
+					"path": downloadPath,
+				},
+			})
+		}
+		job.Steps = append(job.Steps, githubScriptSteps(result, phases["deploy"])...)
+		jobs["deploy"] = job
+	}
+
+	return jobs
+}
+
+// createJob returns a job that checks out the code and sets up the runtime,
+// which every job in the workflow shares. Only jobs with withMatrix set fan
+// out over the version and OS matrix.
+func (g *GithubExecutor) createJob(result *registry.ExtractorResult, opts *registry.Options, withMatrix bool) githubJob {
+	job := githubJob{
+		RunsOn:         "ubuntu-latest",
+		TimeoutMinutes: githubDefaultTimeoutMinutes,
+	}
+	if opts.TimeoutMinutes > 0 {
+		job.TimeoutMinutes = opts.TimeoutMinutes
+	}
+	if len(opts.OS) > 0 {
+		job.RunsOn = opts.OS[0]
+	}
+	version := result.RuntimeVersion
+
+	matrix := make(map[string][]string)
+	if key := githubVersionInput(result.Runtime); withMatrix && key != "" && len(result.RuntimeVersions) > 1 {
+		matrix[key] = result.RuntimeVersions
+		version = fmt.Sprintf("${{ matrix.%s }}", key)
+	}
+	if withMatrix && len(opts.OS) > 1 {
+		matrix["os"] = opts.OS
+		job.RunsOn = "${{ matrix.os }}"
+	}
+	if len(matrix) > 0 {
+		job.Strategy = &githubStrategy{
+			FailFast: opts.FailFast,
+			Matrix:   matrix,
+		}
+	}
+
+	job.Steps = []githubStep{
 		{Name: "Checkout", Uses: "actions/checkout@v4"},
 	}
 
-	setupSteps := g.createSetupSteps(result)
-	steps = append(steps, setupSteps...)
+	setupSteps := g.createSetupSteps(result, version)
+	job.Steps = append(job.Steps, setupSteps...)
+	if result.PackageManager == "conda" {
+		// Only a login shell activates the conda environment.
+		job.Defaults = &githubDefaults{Run: githubRunDefaults{Shell: "bash -el {0}"}}
+	}
 
-	for stepName, command := range result.Scripts {
-		steps = append(steps, githubStep{
-			Name: stepName,
-			Run:  command,
+	if opts.Layout == registry.LayoutSplit && githubInstallsScript(result, "Install") {
+		job.Steps = append(job.Steps, githubStep{
+			Name: "Install",
+			Run:  result.Scripts["Install"],
 		})
 	}
 
-	workflow := githubWorkflow{
-		Name: name,
-		On: githubOn{
-			Push: &githubPushEvent{
-				Branches: []string{"main"},
-			},
-		},
-		Jobs: map[string]githubJob{
-			"build": {
-				RunsOn: "ubuntu-latest",
-				Steps:  steps,
-			},
-		},
+	return job
+}
+
+// githubScriptSteps runs the scripts for keys, passing the service
+// connection variables to the test scripts.
+func githubScriptSteps(result *registry.ExtractorResult, keys []string) []githubStep {
+	var steps []githubStep
+	for _, key := range keys {
+		if !githubInstallsScript(result, key) {
+			continue
+		}
+		step := githubStep{
+			Name: key,
+			Run:  result.Scripts[key],
+		}
+		if scriptPhase(key) == "test" {
+			step.Env = serviceEnv(result.Services, true)
+		}
+		steps = append(steps, step)
 	}
+	return steps
+}
+
+// githubInstallsScript reports whether the workflow runs the script as a
+// step. setup-miniconda already creates the conda environment from the
+// environment file, so the Install script is left out.
+func githubInstallsScript(result *registry.ExtractorResult, key string) bool {
+	if result.Scripts[key] == "" {
+		return false
+	}
+	return key != "Install" || result.PackageManager != "conda"
+}
 
-	data, err := yaml.Marshal(&workflow)
+// githubPrimaryLeg returns the condition that holds on a single leg of the
+// job's matrix, the one running the detected version on the first OS, so
+// deploying and uploading happen once rather than once per leg.
+func githubPrimaryLeg(job githubJob, result *registry.ExtractorResult) string {
+	if job.Strategy == nil {
+		return ""
+	}
+	var conditions []string
+	for _, key := range slices.Sorted(maps.Keys(job.Strategy.Matrix)) {
+		values := job.Strategy.Matrix[key]
+		value := values[0]
+		if key == githubVersionInput(result.Runtime) && slices.Contains(values, result.RuntimeVersion) {
+			value = result.RuntimeVersion
+		}
+		conditions = append(conditions, fmt.Sprintf("matrix.%s == '%s'", key, value))
+	}
+	return strings.Join(conditions, " && ")
+}
+
+// githubConditions combines the non-empty conditions of an if: expression.
+func githubConditions(conditions ...string) string {
+	var parts []string
+	for _, condition := range conditions {
+		if condition != "" {
+			parts = append(parts, condition)
+		}
+	}
+	return strings.Join(parts, " && ")
+}
+
+// githubDeployCondition limits deploying to pushes to the default branch,
+// and to tag pushes when tags trigger the workflow, like the GitLab deploy
+// rules.
+func githubDeployCondition(triggers registry.Triggers) string {
+	branch := "github.ref == format('refs/heads/{0}', github.event.repository.default_branch)"
+	if len(triggers.Tags) > 0 {
+		return "github.event_name == 'push' && (" + branch + " || startsWith(github.ref, 'refs/tags/'))"
+	}
+	return "github.event_name == 'push' && " + branch
+}
+
+// githubSecretsEnv reads the variables the project needs from repository
+// secrets of the same name.
+func githubSecretsEnv(result *registry.ExtractorResult) map[string]string {
+	secrets := RequiredSecrets(result)
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	env := make(map[string]string)
+	for _, secret := range secrets {
+		env[secret.Name] = fmt.Sprintf("${{ secrets.%s }}", secret.Name)
+	}
+	return env
+}
+
+// githubServiceRunners keeps a job with services on Linux runners, the only
+// ones that support service containers. Without a Linux runner in the list
+// the job keeps its runners and goes without the services.
+func githubServiceRunners(opts *registry.Options, services []registry.Service) (*registry.Options, map[string]githubService) {
+	if len(services) == 0 || len(opts.OS) == 0 {
+		return opts, githubServices(services)
+	}
+
+	var linux []string
+	for _, runner := range opts.OS {
+		if strings.HasPrefix(runner, "ubuntu") || strings.Contains(runner, "linux") {
+			linux = append(linux, runner)
+		}
+	}
+	if len(linux) == 0 {
+		return opts, nil
+	}
+	filtered := *opts
+	filtered.OS = linux
+	return &filtered, githubServices(services)
+}
+
+// githubServices runs the services as service containers. Their ports are
+// mapped to the runner, so the steps reach them on localhost.
+func githubServices(services []registry.Service) map[string]githubService {
+	if len(services) == 0 {
+		return nil
+	}
+
+	containers := make(map[string]githubService)
+	for _, service := range services {
+		container := githubService{
+			Image: service.Image,
+			Env:   service.Env,
+		}
+		for _, port := range service.Ports {
+			container.Ports = append(container.Ports, fmt.Sprintf("%d:%d", port.Host, port.Container))
+		}
+		if kind, ok := serviceKinds[serviceKind(service.Image)]; ok && len(service.Ports) == 0 {
+			container.Ports = []string{fmt.Sprintf("%d:%d", kind.port, kind.port)}
+		}
+		if check := service.HealthCheck; check != nil {
+			options := []string{fmt.Sprintf("--health-cmd %q", check.Command)}
+			if check.Interval != "" {
+				options = append(options, "--health-interval "+check.Interval)
+			}
+			if check.Timeout != "" {
+				options = append(options, "--health-timeout "+check.Timeout)
+			}
+			if check.Retries > 0 {
+				options = append(options, fmt.Sprintf("--health-retries %d", check.Retries))
+			}
+			container.Options = strings.Join(options, " ")
+		}
+		containers[service.Name] = container
+	}
+	return containers
+}
+
+func githubTriggers(triggers registry.Triggers, path string) githubOn {
+	triggers = triggersOrDefault(triggers)
+	branches := triggerBranches(triggers, path)
+
+	var on githubOn
+	if triggers.Push || len(triggers.Tags) > 0 {
+		on.Push = &githubPushEvent{
+			Tags:  triggers.Tags,
+			Paths: triggers.Paths,
+		}
+		if triggers.Push {
+			on.Push.Branches = branches
+		}
+	}
+	if triggers.PullRequest {
+		on.PullRequest = &githubPullRequestEvent{
+			Branches: branches,
+			Paths:    triggers.Paths,
+		}
+	}
+	if triggers.Schedule != "" {
+		on.Schedule = []githubSchedule{{Cron: triggers.Schedule}}
+	}
+	if triggers.Dispatch {
+		on.WorkflowDispatch = &githubDispatchEvent{}
+		for name, description := range triggers.Inputs {
+			if on.WorkflowDispatch.Inputs == nil {
+				on.WorkflowDispatch.Inputs = make(map[string]githubDispatchInput)
+			}
+			on.WorkflowDispatch.Inputs[name] = githubDispatchInput{
+				Description: description,
+				Type:        "string",
+			}
+		}
+	}
+	return on
+}
+
+func writeGithubWorkflow(workflow *githubWorkflow, versions map[string]string, workflowDir, name string) (string, error) {
+	var node yaml.Node
+	if err := node.Encode(workflow); err != nil {
+		return "", fmt.Errorf("failed to marshal workflow: %w", err)
+	}
+	annotateActionVersions(&node, versions)
+
+	data, err := yaml.Marshal(&node)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal workflow: %w", err)
 	}
 
-	workflowDir := filepath.Join(path, ".github/workflows")
 	if err := os.MkdirAll(workflowDir, 0755); err != nil {
-		log.Fatal(err)
+		return "", fmt.Errorf("failed to create workflow directory: %w", err)
 	}
 
 	if len(name) == 0 {
@@ -96,62 +521,193 @@ func (g *GithubExecutor) Generate(result *registry.ExtractorResult, path, name s
 	}
 	workflowPath := filepath.Join(workflowDir, fmt.Sprintf("%s.yml", name))
 	if err := os.WriteFile(workflowPath, data, 0644); err != nil {
-		log.Fatal(err)
+		return "", fmt.Errorf("failed to write workflow file: %w", err)
 	}
 
 	return string(data), nil
 }
 
-func (g *GithubExecutor) createSetupSteps(result *registry.ExtractorResult) []githubStep {
+// githubVersionInput returns the setup action input that selects the runtime
+// version, which doubles as the matrix key.
+func githubVersionInput(runtime string) string {
+	switch runtime {
+	case "node":
+		return "node-version"
+	case "go":
+		return "go-version"
+	case "python":
+		return "python-version"
+	}
+	return ""
+}
+
+func (g *GithubExecutor) createSetupSteps(result *registry.ExtractorResult, version string) []githubStep {
 	switch result.Runtime {
 	case "node":
-		return []githubStep{
-			{
-				Name: "Setup Node.js",
-				Uses: "actions/setup-node@v4",
-				With: map[string]string{
-					"node-version": result.RuntimeVersion,
-				},
+		setup := githubStep{
+			Name: "Setup Node.js",
+			Uses: "actions/setup-node@v4",
+			With: map[string]string{
+				"node-version": version,
 			},
 		}
+		if result.Lockfile != "" && result.PackageManager != "bun" {
+			setup.With["cache"] = result.PackageManager
+			setup.With["cache-dependency-path"] = result.Lockfile
+		}
+
+		// setup-node caches pnpm and yarn by asking them for their store,
+		// so they have to be installed first.
+		switch result.PackageManager {
+		case "pnpm":
+			// The action reads a version pinned in the packageManager field
+			// of package.json itself, and fails when a version is passed as
+			// well, since it compares it with the whole field.
+			pnpm := githubStep{Name: "Setup pnpm", Uses: "pnpm/action-setup@v4"}
+			if result.PackageManagerVersion == "" {
+				pnpm.With = map[string]string{"version": "latest"}
+			}
+			return []githubStep{pnpm, setup}
+		case "yarn":
+			return []githubStep{
+				{Name: "Enable Corepack", Run: "corepack enable"},
+				setup,
+			}
+		case "bun":
+			bun := githubStep{Name: "Setup Bun", Uses: "oven-sh/setup-bun@v2"}
+			if result.PackageManagerVersion != "" {
+				bun.With = map[string]string{"bun-version": result.PackageManagerVersion}
+			}
+			steps := []githubStep{setup, bun}
+			return append(steps, githubCacheSteps("bun", "~/.bun/install/cache", result.Lockfile)...)
+		}
+		return []githubStep{setup}
 	case "go":
-		return []githubStep{
-			{
-				Name: "Setup Go",
-				Uses: "actions/setup-go@v5",
-				With: map[string]string{
-					"go-version": result.RuntimeVersion,
-				},
-			},
-			{
-				Name: "Golangci-lint",
-				Uses: "golangci/golangci-lint-action@v7",
+		setup := githubStep{
+			Name: "Setup Go",
+			Uses: "actions/setup-go@v5",
+			With: map[string]string{
+				"go-version": version,
 			},
 		}
+		if result.Lockfile != "" {
+			setup.With["cache-dependency-path"] = result.Lockfile
+		}
+
+		return []githubStep{
+			setup,
+		}
 	case "python":
-		steps := []githubStep{
-			{
-				Name: "Setup Python",
-				Uses: "actions/setup-python@v5",
+		// setup-pdm and setup-miniconda install Python themselves, and
+		// setup-miniconda creates the environment as well.
+		switch result.PackageManager {
+		case "pdm":
+			setup := githubStep{
+				Name: "Setup PDM",
+				Uses: "pdm-project/setup-pdm@v4",
+				With: map[string]string{
+					"python-version": version,
+				},
+			}
+			if result.Lockfile != "" {
+				setup.With["cache"] = "true"
+				setup.With["cache-dependency-path"] = result.Lockfile
+			}
+			return []githubStep{setup}
+		case "conda":
+			setup := githubStep{
+				Name: "Setup Miniforge",
+				Uses: "conda-incubator/setup-miniconda@v3",
 				With: map[string]string{
-					"python-version": result.RuntimeVersion,
+					"miniforge-version":    "latest",
+					"environment-file":     result.Lockfile,
+					"activate-environment": result.Environment,
+					"python-version":       version,
 				},
+			}
+			// The package cache has to be restored before the environment
+			// is created.
+			return append(githubCacheSteps("conda", "~/conda_pkgs_dir", result.Lockfile), setup)
+		}
+
+		setup := githubStep{
+			Name: "Setup Python",
+			Uses: "actions/setup-python@v5",
+			With: map[string]string{
+				"python-version": version,
 			},
 		}
+		steps := []githubStep{setup}
 
 		switch result.PackageManager {
-		case "uv":
+		case "pip", "pipenv":
+			if result.Lockfile != "" {
+				setup.With["cache"] = result.PackageManager
+				setup.With["cache-dependency-path"] = result.Lockfile
+			}
+			if result.PackageManager == "pipenv" {
+				steps = append(steps, githubStep{
+					Name: "Install Pipenv",
+					Run:  "pip install pipenv",
+				})
+			}
+		case "hatch":
 			steps = append(steps, githubStep{
+				Name: "Install Hatch",
+				Uses: "pypa/hatch@install",
+			})
+		case "uv":
+			uv := githubStep{
 				Name: "Install uv",
 				Uses: "astral-sh/setup-uv@v4",
-			})
+			}
+			if result.Lockfile != "" {
+				uv.With = map[string]string{
+					"enable-cache":          "true",
+					"cache-dependency-glob": result.Lockfile,
+				}
+			}
+			steps = append(steps, uv)
 		case "poetry":
 			steps = append(steps, githubStep{
 				Name: "Install Poetry",
 				Uses: "snok/install-poetry@v1",
 			})
+			steps = append(steps, githubCacheSteps("poetry", "~/.cache/pypoetry", result.Lockfile)...)
 		}
 		return steps
 	}
 	return nil
 }
+
+// createLintSteps returns the lint actions that run in place of a lint
+// script.
+func (g *GithubExecutor) createLintSteps(result *registry.ExtractorResult) []githubStep {
+	switch result.Runtime {
+	case "go":
+		return []githubStep{
+			{
+				Name: "Golangci-lint",
+				Uses: "golangci/golangci-lint-action@v7",
+			},
+		}
+	}
+	return nil
+}
+
+// githubCacheSteps caches a package manager's download directory with
+// actions/cache, for managers the setup actions cannot cache themselves.
+func githubCacheSteps(manager, path, lockfile string) []githubStep {
+	if lockfile == "" {
+		return nil
+	}
+	return []githubStep{{
+		Name: "Cache " + manager,
+		Uses: "actions/cache@v4",
+		With: map[string]string{
+			"path":         path,
+			"key":          fmt.Sprintf("${{ runner.os }}-%s-${{ hashFiles('%s') }}", manager, lockfile),
+			"restore-keys": fmt.Sprintf("${{ runner.os }}-%s-", manager),
+		},
+	}}
+}
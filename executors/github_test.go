@@ -0,0 +1,91 @@
+package executors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+func TestGithubGolden(t *testing.T) {
+	buildOnly := testNodeResult()
+	delete(buildOnly.Scripts, "Test")
+	delete(buildOnly.Scripts, "Lint")
+	buildOnly.TestReport = nil
+
+	goResult := &registry.ExtractorResult{
+		Runtime:         "go",
+		RuntimeVersion:  "1.22",
+		RuntimeVersions: []string{"1.22", "1.24.1"},
+		Image:           "golang:1.22",
+		PackageManager:  "go",
+		Lockfile:        "go.sum",
+		Scripts: map[string]string{
+			"Build": "go build -v ./...",
+			"Test":  "go test -v ./...",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		result *registry.ExtractorResult
+		opts   registry.Options
+	}{
+		{
+			name:   "node-single",
+			result: testNodeResult(),
+			opts:   registry.Options{Name: "ci", OS: []string{"ubuntu-latest", "macos-latest"}, Triggers: registry.Triggers{Push: true, Tags: []string{"v*"}}},
+		},
+		{
+			name:   "node-split",
+			result: testNodeResult(),
+			opts:   registry.Options{Name: "ci", Layout: registry.LayoutSplit, Triggers: registry.Triggers{Push: true, PullRequest: true}},
+		},
+		{
+			name:   "node-split-without-tests",
+			result: buildOnly,
+			opts:   registry.Options{Name: "ci", Layout: registry.LayoutSplit, OS: []string{"ubuntu-latest", "windows-latest"}},
+		},
+		{
+			name:   "go-toolchain",
+			result: goResult,
+			opts:   registry.Options{Name: "ci"},
+		},
+		{
+			name:   "python",
+			result: testPythonResult(),
+			opts:   registry.Options{Name: "ci", Triggers: registry.Triggers{Schedule: "0 3 * * 1", Dispatch: true}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := generate(t, &GithubExecutor{}, test.result, &test.opts)
+			checkGolden(t, "github/"+test.name, output)
+		})
+	}
+}
+
+func TestGithubPnpmVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		want    bool
+	}{
+		// pnpm/action-setup reads the packageManager field on its own.
+		{"pinned in package.json", "9.12.0", false},
+		{"lockfile only", "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := testNodeResult()
+			result.PackageManagerVersion = test.version
+			output := generate(t, &GithubExecutor{}, result, &registry.Options{Name: "ci"})
+			if got := strings.Contains(output, "version: latest"); got != test.want {
+				t.Errorf("output has version: latest = %v, want %v:\n%s", got, test.want, output)
+			}
+			if strings.Contains(output, "version: 9.12.0") {
+				t.Errorf("output passes the pinned version to pnpm/action-setup:\n%s", output)
+			}
+		})
+	}
+}
@@ -2,12 +2,11 @@ package executors
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/zraisan/AutoFlow/registry"
-	"gopkg.in/yaml.v3"
 )
 
 func init() {
@@ -16,47 +15,233 @@ func init() {
 
 type GitlabExecutor struct{}
 
+// gitlabGeneratedHeader marks a .gitlab-ci.yml written by AutoFlow, which is
+// safe to overwrite on the next run.
+const gitlabGeneratedHeader = "# Generated by AutoFlow.\n"
+
+// gitlabCacheDirs lists the directories worth caching per package manager,
+// along with the variables that move them inside the project directory,
+// where GitLab can cache them.
+var gitlabCacheDirs = map[string]struct {
+	paths     []string
+	variables map[string]string
+}{
+	"npm":    {paths: []string{"node_modules/"}},
+	"pnpm":   {paths: []string{"node_modules/"}},
+	"yarn":   {paths: []string{"node_modules/"}},
+	"bun":    {paths: []string{"node_modules/"}},
+	"pip":    {paths: []string{".cache/pip/"}, variables: map[string]string{"PIP_CACHE_DIR": "$CI_PROJECT_DIR/.cache/pip"}},
+	"uv":     {paths: []string{".cache/uv/"}, variables: map[string]string{"UV_CACHE_DIR": "$CI_PROJECT_DIR/.cache/uv"}},
+	"poetry": {paths: []string{".cache/pypoetry/"}, variables: map[string]string{"POETRY_CACHE_DIR": "$CI_PROJECT_DIR/.cache/pypoetry"}},
+	"pdm":    {paths: []string{".cache/pdm/"}, variables: map[string]string{"PDM_CACHE_DIR": "$CI_PROJECT_DIR/.cache/pdm"}},
+	"pipenv": {paths: []string{".cache/pip/", ".venv/"}, variables: map[string]string{"PIP_CACHE_DIR": "$CI_PROJECT_DIR/.cache/pip", "PIPENV_VENV_IN_PROJECT": "1"}},
+	"conda":  {paths: []string{".cache/conda/"}, variables: map[string]string{"CONDA_PKGS_DIRS": "$CI_PROJECT_DIR/.cache/conda"}},
+	"go":     {paths: []string{".go/pkg/mod/"}, variables: map[string]string{"GOPATH": "$CI_PROJECT_DIR/.go"}},
+}
+
 type gitlabWorkflow struct {
-	Stages []string             `yaml:"stages"`
-	Jobs   map[string]gitlabJob `yaml:",inline"`
+	Workflow  *gitlabWorkflowRules      `yaml:"workflow,omitempty"`
+	Variables map[string]gitlabVariable `yaml:"variables,omitempty"`
+	Default   *gitlabDefault            `yaml:"default,omitempty"`
+	Stages    []string                  `yaml:"stages,omitempty"`
+	Jobs      map[string]gitlabJob      `yaml:",inline"`
+}
+
+type gitlabDefault struct {
+	Image        string       `yaml:"image,omitempty"`
+	BeforeScript []string     `yaml:"before_script,omitempty"`
+	Cache        *gitlabCache `yaml:"cache,omitempty"`
+}
+
+type gitlabCache struct {
+	Key   gitlabCacheKey `yaml:"key"`
+	Paths []string       `yaml:"paths"`
+}
+
+type gitlabCacheKey struct {
+	Files []string `yaml:"files"`
+}
+
+type gitlabWorkflowRules struct {
+	Rules []gitlabRule `yaml:"rules"`
+}
+
+type gitlabRule struct {
+	If      string   `yaml:"if,omitempty"`
+	Changes []string `yaml:"changes,omitempty"`
+	When    string   `yaml:"when,omitempty"`
+	StartIn string   `yaml:"start_in,omitempty"`
+}
+
+type gitlabVariable struct {
+	Value       string `yaml:"value"`
+	Description string `yaml:"description,omitempty"`
 }
 
 type gitlabJob struct {
-	Stage     string          `yaml:"stage"`
-	Image     string          `yaml:"image,omitempty"`
-	Script    []string        `yaml:"script"`
-	Only      []string        `yaml:"only,omitempty"`
-	Artifacts gitlabArtifacts `yaml:"artifacts,omitempty"`
+	gitlabDefault `yaml:",inline"`
+
+	Extends       string            `yaml:"extends,omitempty"`
+	Stage         string            `yaml:"stage,omitempty"`
+	Needs         *[]string         `yaml:"needs,omitempty"`
+	Interruptible bool              `yaml:"interruptible,omitempty"`
+	Services      []gitlabService   `yaml:"services,omitempty"`
+	Variables     map[string]string `yaml:"variables,omitempty"`
+	Script        []string          `yaml:"script,omitempty"`
+	Rules         []gitlabRule      `yaml:"rules,omitempty"`
+	Artifacts     *gitlabArtifacts  `yaml:"artifacts,omitempty"`
+}
+
+type gitlabService struct {
+	Name      string            `yaml:"name"`
+	Alias     string            `yaml:"alias"`
+	Variables map[string]string `yaml:"variables,omitempty"`
 }
 
 type gitlabArtifacts struct {
-	Paths []string `yaml:"paths,omitempty"`
+	When     string         `yaml:"when,omitempty"`
+	Paths    []string       `yaml:"paths,omitempty"`
+	ExpireIn string         `yaml:"expire_in,omitempty"`
+	Reports  *gitlabReports `yaml:"reports,omitempty"`
+}
+
+type gitlabReports struct {
+	JUnit          string                `yaml:"junit,omitempty"`
+	CoverageReport *gitlabCoverageReport `yaml:"coverage_report,omitempty"`
+}
+
+type gitlabCoverageReport struct {
+	CoverageFormat string `yaml:"coverage_format"`
+	Path           string `yaml:"path"`
 }
 
 func (g *GitlabExecutor) Name() string {
 	return "Gitlab"
 }
 
-func (g *GitlabExecutor) Generate(result *registry.ExtractorResult, path, name string) (string, error) {
+func (g *GitlabExecutor) Generate(result *registry.ExtractorResult, path string, opts *registry.Options) (string, error) {
+	existing, err := readGitlabRoot(path)
+	if err != nil {
+		return "", err
+	}
+
+	// A pipeline included from a hand-written root file shares its job names
+	// and global keywords, so its jobs are prefixed with the pipeline name and
+	// carry the image, install step, cache, variables and trigger rules
+	// themselves instead of declaring them for every job of the root file.
+	pipeline := gitlabPipelineName(opts.Name)
+	included := existing != nil
+	prefix := ""
+	if included {
+		prefix = pipeline + ":"
+	}
+	base := "." + prefix + "base"
+
+	phases := make(map[string][]string)
+	for _, key := range orderedScripts(result.Scripts) {
+		phase := scriptPhase(key)
+		if phase == "install" {
+			continue
+		}
+		phases[phase] = append(phases[phase], key)
+	}
+
+	defaults := gitlabDefault{Image: result.Image, BeforeScript: installCommands(result)}
+	variables := make(map[string]gitlabVariable)
+	if dirs, ok := gitlabCacheDirs[result.PackageManager]; ok && result.Lockfile != "" {
+		defaults.Cache = &gitlabCache{
+			Key:   gitlabCacheKey{Files: []string{result.Lockfile}},
+			Paths: dirs.paths,
+		}
+		for name, value := range dirs.variables {
+			variables[name] = gitlabVariable{Value: value}
+		}
+	}
+	// Secrets are set as CI/CD variables in the project settings, which take
+	// precedence over these empty placeholders.
+	for _, secret := range RequiredSecrets(result) {
+		variables[secret.Name] = gitlabVariable{Description: secretDescription(secret)}
+	}
+
+	triggerRules := gitlabTriggerRules(opts.Triggers, path)
+	template := gitlabJob{
+		Interruptible: true,
+		Rules:         gitlabChangesRules(opts.Triggers.Paths),
+	}
+	deployRules := gitlabDeployRules(opts)
+	var jobVariables map[string]string
+	if included {
+		template.gitlabDefault = defaults
+		template.Rules = gitlabScopeRules(triggerRules, template.Rules)
+		deployRules = gitlabScopeRules(triggerRules, deployRules)
+		for name, variable := range variables {
+			if jobVariables == nil {
+				jobVariables = make(map[string]string)
+			}
+			jobVariables[name] = variable.Value
+		}
+		template.Variables = jobVariables
+	}
+
 	var stages []string
-	jobs := make(map[string]gitlabJob)
+	jobs := map[string]gitlabJob{base: template}
 
-	for key, value := range result.Scripts {
-		if key == "Install" {
+	// Lint and test jobs start right away and run in parallel, later phases
+	// wait only for the jobs of the phase before them.
+	var previous []string
+	for _, phase := range lifecyclePhases {
+		keys := phases[phase]
+		if len(keys) == 0 {
 			continue
 		}
-		stages = append(stages, strings.ToLower(key))
+		stages = append(stages, phase)
 
-		var script []string
-		if result.Scripts["Install"] != "" {
-			script = append(script, result.Scripts["Install"])
+		needs := slices.Clone(previous)
+		if phase == "lint" || phase == "test" {
+			needs = []string{}
+		} else {
+			previous = nil
 		}
-		script = append(script, value)
 
-		jobs[key] = gitlabJob{
-			Stage:  strings.ToLower(key),
-			Image:  result.Image,
-			Script: script,
+		for _, key := range keys {
+			job := gitlabJob{
+				Stage:  phase,
+				Needs:  &needs,
+				Script: []string{result.Scripts[key]},
+			}
+			if phase == "deploy" {
+				// The deploy job does not extend the template, so it is
+				// never interrupted by a newer pipeline.
+				job.Rules = deployRules
+				if included {
+					job.gitlabDefault = defaults
+					job.Variables = maps.Clone(jobVariables)
+				}
+			} else {
+				job.Extends = base
+			}
+			if phase == "build" && len(result.Artifacts) > 0 {
+				job.Artifacts = &gitlabArtifacts{
+					Paths:    result.Artifacts,
+					ExpireIn: "1 week",
+				}
+			}
+			if phase == "test" {
+				for _, service := range result.Services {
+					job.Services = append(job.Services, gitlabService{
+						Name:      service.Image,
+						Alias:     service.Name,
+						Variables: service.Env,
+					})
+				}
+				job.Variables = serviceEnv(result.Services, false)
+			}
+			if key == "Test" && result.TestReport != nil {
+				job.Script = []string{result.TestReport.Script}
+				job.Artifacts = gitlabTestArtifacts(result.TestReport)
+			}
+			jobs[prefix+key] = job
+			previous = append(previous, prefix+key)
 		}
 	}
 
@@ -64,16 +249,154 @@ func (g *GitlabExecutor) Generate(result *registry.ExtractorResult, path, name s
 		Stages: stages,
 		Jobs:   jobs,
 	}
+	if !included {
+		workflow.Workflow = &gitlabWorkflowRules{Rules: triggerRules}
+		workflow.Default = &defaults
+		workflow.Variables = variables
+	}
+	// Inputs prefill the form of a manually run pipeline, which only reads
+	// global variables.
+	if opts.Triggers.Dispatch {
+		for name, description := range opts.Triggers.Inputs {
+			if workflow.Variables == nil {
+				workflow.Variables = make(map[string]gitlabVariable)
+			}
+			workflow.Variables[name] = gitlabVariable{Description: description}
+		}
+	}
 
-	data, err := yaml.Marshal(&workflow)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal workflow: %w", err)
+	return writeGitlabWorkflow(path, pipeline, existing, workflow)
+}
+
+// gitlabScopeRules limits job rules to the pipelines the workflow rules would
+// create, for an included pipeline that cannot set workflow rules without
+// applying them to the root pipeline too. Every rule that lets a pipeline
+// run is combined with each job rule.
+func gitlabScopeRules(workflow, job []gitlabRule) []gitlabRule {
+	if len(job) == 0 {
+		return workflow
 	}
+	var rules []gitlabRule
+	for _, pipeline := range workflow {
+		if pipeline.When == "never" {
+			rules = append(rules, pipeline)
+			continue
+		}
+		for _, rule := range job {
+			switch {
+			case pipeline.If == "":
+			case rule.If == "":
+				rule.If = pipeline.If
+			default:
+				rule.If = "(" + pipeline.If + ") && (" + rule.If + ")"
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
 
-	workflowFilepath := filepath.Join(path, fmt.Sprintf(".gitlab-ci-%s.yml", name))
-	if err := os.WriteFile(workflowFilepath, data, 0644); err != nil {
-		return "", fmt.Errorf("failed to write workflow file: %w", err)
+// gitlabChangesRules skips check and build jobs in branch and merge request
+// pipelines that do not touch any of paths. Tag, scheduled and manual
+// pipelines always run them.
+func gitlabChangesRules(paths []string) []gitlabRule {
+	if len(paths) == 0 {
+		return nil
+	}
+	const pushOrMergeRequest = `$CI_PIPELINE_SOURCE == "merge_request_event" || ($CI_PIPELINE_SOURCE == "push" && $CI_COMMIT_BRANCH)`
+	return []gitlabRule{
+		{If: pushOrMergeRequest, Changes: paths},
+		{If: pushOrMergeRequest, When: "never"},
+		{When: "on_success"},
+	}
+}
+
+// gitlabDeployRules run the deploy job on the default branch, and on tags
+// when tags trigger the pipeline. With paths, branch pipelines that skip the
+// build job skip deploying too, since the deploy job needs the build job.
+func gitlabDeployRules(opts *registry.Options) []gitlabRule {
+	rule := gitlabRule{When: opts.DeployWhen}
+	switch opts.DeployWhen {
+	case "":
+		rule.When = registry.DeployManual
+	case registry.DeployDelayed:
+		rule.StartIn = opts.DeployDelay
+		if rule.StartIn == "" {
+			rule.StartIn = "30 minutes"
+		}
 	}
 
-	return string(data), nil
+	const branch = "$CI_COMMIT_BRANCH == $CI_DEFAULT_BRANCH"
+	tags := len(opts.Triggers.Tags) > 0
+	if len(opts.Triggers.Paths) == 0 {
+		rule.If = branch
+		if tags {
+			rule.If = "$CI_COMMIT_TAG || " + branch
+		}
+		return []gitlabRule{rule}
+	}
+
+	var rules []gitlabRule
+	if tags {
+		tag := rule
+		tag.If = "$CI_COMMIT_TAG"
+		rules = append(rules, tag)
+	}
+	rule.If = branch
+	rule.Changes = opts.Triggers.Paths
+	return append(rules, rule)
+}
+
+func gitlabTestArtifacts(report *registry.TestReport) *gitlabArtifacts {
+	artifacts := &gitlabArtifacts{
+		When:     "always",
+		ExpireIn: "1 week",
+		Reports:  &gitlabReports{JUnit: report.JUnit},
+	}
+	if report.Coverage != "" {
+		artifacts.Reports.CoverageReport = &gitlabCoverageReport{
+			CoverageFormat: "cobertura",
+			Path:           report.Coverage,
+		}
+	}
+	return artifacts
+}
+
+// gitlabTriggerRules translates the selected triggers into workflow rules, so
+// a pipeline is only created for the matching events.
+func gitlabTriggerRules(triggers registry.Triggers, path string) []gitlabRule {
+	triggers = triggersOrDefault(triggers)
+
+	var rules []gitlabRule
+	if triggers.PullRequest {
+		// Run merge request pipelines, and skip the duplicate branch pipeline
+		// for pushes to a branch that has an open merge request.
+		rules = append(rules,
+			gitlabRule{If: `$CI_PIPELINE_SOURCE == "merge_request_event"`},
+			gitlabRule{If: `$CI_COMMIT_BRANCH && $CI_OPEN_MERGE_REQUESTS && $CI_PIPELINE_SOURCE == "push"`, When: "never"},
+		)
+	}
+	if triggers.Push {
+		var conditions []string
+		for _, branch := range triggerBranches(triggers, path) {
+			if strings.ContainsAny(branch, "*?") {
+				conditions = append(conditions, "$CI_COMMIT_BRANCH =~ "+globToRegexp(branch))
+			} else {
+				conditions = append(conditions, fmt.Sprintf("$CI_COMMIT_BRANCH == %q", branch))
+			}
+		}
+		rules = append(rules, gitlabRule{
+			If: `$CI_PIPELINE_SOURCE == "push" && (` + strings.Join(conditions, " || ") + ")",
+		})
+	}
+	for _, tag := range triggers.Tags {
+		rules = append(rules, gitlabRule{If: "$CI_COMMIT_TAG =~ " + globToRegexp(tag)})
+	}
+	if triggers.Schedule != "" {
+		rules = append(rules, gitlabRule{If: `$CI_PIPELINE_SOURCE == "schedule"`})
+	}
+	if triggers.Dispatch {
+		rules = append(rules, gitlabRule{If: `$CI_PIPELINE_SOURCE == "web"`})
+	}
+	return rules
 }
@@ -0,0 +1,121 @@
+package executors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+func TestGitlabGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *registry.ExtractorResult
+		opts   registry.Options
+	}{
+		{
+			name:   "node",
+			result: testNodeResult(),
+			opts:   registry.Options{Name: "ci", Triggers: registry.Triggers{Push: true, PullRequest: true, Tags: []string{"v*"}}},
+		},
+		{
+			name:   "node-paths",
+			result: testNodeResult(),
+			opts:   registry.Options{Name: "ci", DeployWhen: registry.DeployDelayed, Triggers: registry.Triggers{Push: true, Paths: []string{"src/**"}}},
+		},
+		{
+			name:   "python",
+			result: testPythonResult(),
+			opts:   registry.Options{Name: "ci", Triggers: registry.Triggers{Schedule: "0 3 * * 1", Dispatch: true, Inputs: map[string]string{"TARGET": "Where to deploy"}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := generate(t, &GitlabExecutor{}, test.result, &test.opts)
+			checkGolden(t, "gitlab/"+test.name, output)
+		})
+	}
+}
+
+func TestGitlabIncluded(t *testing.T) {
+	path := t.TempDir()
+	root := "# Hand-written pipeline.\nstages: [build]\n\nbuild:\n  script: make\n"
+	if err := os.WriteFile(filepath.Join(path, ".gitlab-ci.yml"), []byte(root), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &registry.Options{Name: "web", Triggers: registry.Triggers{Push: true, PullRequest: true}}
+	output, err := (&GitlabExecutor{}).Generate(testNodeResult(), path, opts)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	checkGolden(t, "gitlab/node-included", output)
+
+	included, err := os.ReadFile(filepath.Join(path, "ci/web.yml"))
+	if err != nil {
+		t.Fatalf("failed to read included pipeline: %v", err)
+	}
+	if string(included) != output {
+		t.Errorf("ci/web.yml differs from the returned pipeline")
+	}
+	for _, global := range []string{"\nworkflow:", "\ndefault:", "\nvariables:", "\nstages:"} {
+		if strings.Contains(output, global) {
+			t.Errorf("included pipeline declares %q, which applies to the root pipeline", strings.TrimSpace(global))
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(path, ".gitlab-ci.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Hand-written pipeline.\ninclude:\n  - local: ci/web.yml\nstages: [lint, test, build, deploy]\n\nbuild:\n  script: make\n"
+	if string(data) != want {
+		t.Errorf("root pipeline =\n%s\nwant\n%s", data, want)
+	}
+
+	// A second run finds the include already in place.
+	if _, err := (&GitlabExecutor{}).Generate(testNodeResult(), path, opts); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	again, err := os.ReadFile(filepath.Join(path, ".gitlab-ci.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != want {
+		t.Errorf("second run changed the root pipeline:\n%s", again)
+	}
+}
+
+func TestGitlabScopeRules(t *testing.T) {
+	workflow := []gitlabRule{
+		{If: "$A"},
+		{If: "$B", When: "never"},
+		{If: "$C"},
+	}
+	job := []gitlabRule{
+		{If: "$X", Changes: []string{"src/**"}},
+		{When: "on_success"},
+	}
+	want := []gitlabRule{
+		{If: "($A) && ($X)", Changes: []string{"src/**"}},
+		{If: "$A", When: "on_success"},
+		{If: "$B", When: "never"},
+		{If: "($C) && ($X)", Changes: []string{"src/**"}},
+		{If: "$C", When: "on_success"},
+	}
+	got := gitlabScopeRules(workflow, job)
+	if len(got) != len(want) {
+		t.Fatalf("gitlabScopeRules() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].If != want[i].If || got[i].When != want[i].When || strings.Join(got[i].Changes, ",") != strings.Join(want[i].Changes, ",") {
+			t.Errorf("rule %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := gitlabScopeRules(workflow, nil); len(got) != len(workflow) {
+		t.Errorf("gitlabScopeRules() without job rules = %+v, want the workflow rules", got)
+	}
+}
@@ -0,0 +1,357 @@
+package executors
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// gitlabDefaultStages are the stages GitLab runs when a pipeline declares
+// none.
+var gitlabDefaultStages = []string{"build", "test", "deploy"}
+
+// readGitlabRoot returns the hand-written root pipeline of the project, or
+// nil when there is none or it was generated by AutoFlow, which is safe to
+// overwrite on the next run.
+func readGitlabRoot(path string) ([]byte, error) {
+	rootPath := filepath.Join(path, ".gitlab-ci.yml")
+	existing, err := os.ReadFile(rootPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rootPath, err)
+	}
+	if strings.HasPrefix(string(existing), gitlabGeneratedHeader) {
+		return nil, nil
+	}
+	return existing, nil
+}
+
+func gitlabPipelineName(name string) string {
+	if len(name) == 0 {
+		return "autoflow"
+	}
+	return name
+}
+
+// writeGitlabWorkflow writes the pipeline to .gitlab-ci.yml, which is the only
+// file GitLab reads. A hand-written root file is kept as it is: the pipeline
+// is written under ci/ and included from the root file, which also declares
+// the stages, since an included file would override them.
+func writeGitlabWorkflow(path, name string, existing []byte, workflow *gitlabWorkflow) (string, error) {
+	rootPath := filepath.Join(path, ".gitlab-ci.yml")
+	if existing == nil {
+		data, err := marshalGitlabWorkflow(workflow)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(rootPath, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write workflow file: %w", err)
+		}
+		return string(data), nil
+	}
+
+	include := "ci/" + name + ".yml"
+	stages := workflow.Stages
+	workflow.Stages = nil
+	data, err := marshalGitlabWorkflow(workflow)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Join(path, "ci"), 0755); err != nil {
+		return "", fmt.Errorf("failed to create ci directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, include), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write workflow file: %w", err)
+	}
+
+	root, err := addGitlabInclude(existing, include, stages)
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", rootPath, err)
+	}
+	if err := os.WriteFile(rootPath, root, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", rootPath, err)
+	}
+	return string(data), nil
+}
+
+func marshalGitlabWorkflow(workflow *gitlabWorkflow) ([]byte, error) {
+	data, err := yaml.Marshal(workflow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal workflow: %w", err)
+	}
+	return append([]byte(gitlabGeneratedHeader), data...), nil
+}
+
+// gitlabRoot is a hand-written root pipeline that is edited line by line,
+// so its formatting, comments and anchors survive. The parsed document only
+// locates the lines to change.
+type gitlabRoot struct {
+	lines   []string
+	mapping *yaml.Node
+}
+
+// addGitlabInclude adds an "include: local:" entry for file to a root
+// pipeline, accepting every form the include keyword can already take, and
+// adds the stages the included jobs run in. The file is parsed again after
+// the first edit, so the second sees the current line numbers.
+func addGitlabInclude(data []byte, file string, stages []string) ([]byte, error) {
+	root, err := parseGitlabRoot(data)
+	if err != nil {
+		return nil, err
+	}
+	root.addInclude(file)
+
+	root, err = parseGitlabRoot(root.bytes())
+	if err != nil {
+		return nil, err
+	}
+	root.addStages(stages)
+	return root.bytes(), nil
+}
+
+func parseGitlabRoot(data []byte) (*gitlabRoot, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	root := &gitlabRoot{lines: strings.SplitAfter(string(data), "\n")}
+	if n := len(root.lines); root.lines[n-1] == "" {
+		root.lines = root.lines[:n-1]
+	} else {
+		root.lines[n-1] += "\n"
+	}
+	if len(doc.Content) > 0 {
+		root.mapping = doc.Content[0]
+		if root.mapping.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("expected a mapping at the top level")
+		}
+	}
+	return root, nil
+}
+
+func (r *gitlabRoot) bytes() []byte {
+	return []byte(strings.Join(r.lines, ""))
+}
+
+// key returns the index of a top-level key in the mapping, or -1.
+func (r *gitlabRoot) key(name string) int {
+	if r.mapping == nil {
+		return -1
+	}
+	for i := 0; i+1 < len(r.mapping.Content); i += 2 {
+		if r.mapping.Content[i].Value == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// valueEnd returns the index of the line after the value of the key at i,
+// leaving out blank lines and comments that lead into the next key.
+func (r *gitlabRoot) valueEnd(i int) int {
+	end := len(r.lines)
+	if i+2 < len(r.mapping.Content) {
+		end = r.mapping.Content[i+2].Line - 1
+	}
+	start := r.mapping.Content[i].Line
+	for end > start && isBlankOrComment(r.lines[end-1]) {
+		end--
+	}
+	return end
+}
+
+// insert adds lines before the line at index at.
+func (r *gitlabRoot) insert(at int, lines ...string) {
+	r.lines = slices.Insert(r.lines, at, lines...)
+}
+
+func (r *gitlabRoot) addInclude(file string) {
+	entry := "local: " + file
+	i := r.key("include")
+	if i < 0 {
+		// Leading comments stay on top, the include goes right after them.
+		at := 0
+		for at < len(r.lines) && strings.HasPrefix(strings.TrimSpace(r.lines[at]), "#") {
+			at++
+		}
+		r.insert(at, "include:\n", "  - "+entry+"\n")
+		return
+	}
+
+	key, value := r.mapping.Content[i], r.mapping.Content[i+1]
+	includes := []*yaml.Node{value}
+	if value.Kind == yaml.SequenceNode {
+		includes = value.Content
+	}
+	for _, include := range includes {
+		if gitlabIncludesFile(include, file) {
+			return
+		}
+	}
+
+	end := r.valueEnd(i)
+	switch {
+	case value.Kind == yaml.SequenceNode && value.Style&yaml.FlowStyle != 0:
+		r.appendFlowItem(key.Line-1, end, "{"+entry+"}")
+	case value.Kind == yaml.SequenceNode:
+		r.insert(end, strings.Repeat(" ", value.Column-1)+"- "+entry+"\n")
+	case value.Line == key.Line:
+		// "include: file.yml" or "include: {local: file.yml}" becomes a
+		// list holding the original entry.
+		line := r.lines[key.Line-1]
+		original := line[value.Column-1:] + strings.Join(r.lines[key.Line:end], "")
+		r.lines = slices.Replace(r.lines, key.Line-1, end,
+			strings.TrimRight(line[:value.Column-1], " ")+"\n",
+			"  - "+strings.TrimRight(original, "\n")+"\n",
+			"  - "+entry+"\n",
+		)
+	default:
+		// A block mapping below the key becomes the first item of a list.
+		indent := strings.Repeat(" ", value.Column-1)
+		for l := value.Line - 1; l < end; l++ {
+			switch {
+			case l == value.Line-1:
+				r.lines[l] = indent + "- " + r.lines[l][value.Column-1:]
+			case strings.TrimSpace(r.lines[l]) != "":
+				r.lines[l] = "  " + r.lines[l]
+			}
+		}
+		r.insert(end, indent+"- "+entry+"\n")
+	}
+}
+
+// addStages declares the stages the included jobs need. Missing stages are
+// placed next to the lifecycle stages already declared; without a stages key,
+// GitLab's default stages are declared along with them in lifecycle order.
+func (r *gitlabRoot) addStages(stages []string) {
+	if len(stages) == 0 {
+		return
+	}
+
+	i := r.key("stages")
+	if i < 0 {
+		var declared []string
+		for _, phase := range lifecyclePhases {
+			if slices.Contains(stages, phase) || slices.Contains(gitlabDefaultStages, phase) {
+				declared = append(declared, phase)
+			}
+		}
+		lines := []string{"stages:\n"}
+		for _, stage := range declared {
+			lines = append(lines, "  - "+stage+"\n")
+		}
+		at := len(r.lines)
+		if include := r.key("include"); include >= 0 {
+			at = r.valueEnd(include)
+		} else if r.mapping != nil && len(r.mapping.Content) > 0 {
+			at = r.mapping.Content[0].Line - 1
+		}
+		r.insert(at, lines...)
+		return
+	}
+
+	value := r.mapping.Content[i+1]
+	if value.Kind != yaml.SequenceNode {
+		return
+	}
+	var existing []string
+	for _, item := range value.Content {
+		existing = append(existing, item.Value)
+	}
+	merged := mergeStages(existing, stages)
+	if len(merged) == len(existing) {
+		return
+	}
+
+	if value.Style&yaml.FlowStyle != 0 {
+		key := r.mapping.Content[i]
+		end := r.valueEnd(i)
+		line := r.lines[key.Line-1]
+		r.lines = slices.Replace(r.lines, key.Line-1, end, line[:value.Column-1]+"["+strings.Join(merged, ", ")+"]\n")
+		return
+	}
+
+	// Insert bottom-up so the lines of the items not yet handled stay put.
+	indent := strings.Repeat(" ", value.Column-1) + "- "
+	end := r.valueEnd(i)
+	for j := len(merged) - 1; j >= 0; j-- {
+		stage := merged[j]
+		if slices.Contains(existing, stage) {
+			continue
+		}
+		at := end
+		for _, next := range merged[j+1:] {
+			if k := slices.Index(existing, next); k >= 0 {
+				at = value.Content[k].Line - 1
+				break
+			}
+		}
+		r.insert(at, indent+stage+"\n")
+	}
+}
+
+// mergeStages adds the missing stages to existing, each right before the
+// next stage of the pipeline that is already declared.
+func mergeStages(existing, stages []string) []string {
+	merged := slices.Clone(existing)
+	for j := len(stages) - 1; j >= 0; j-- {
+		if slices.Contains(merged, stages[j]) {
+			continue
+		}
+		at := len(merged)
+		for _, next := range stages[j+1:] {
+			if k := slices.Index(merged, next); k >= 0 {
+				at = k
+				break
+			}
+		}
+		merged = slices.Insert(merged, at, stages[j])
+	}
+	return merged
+}
+
+// appendFlowItem adds item to the end of the flow sequence on lines
+// [start, end), such as "include: [a.yml, b.yml]".
+func (r *gitlabRoot) appendFlowItem(start, end int, item string) {
+	for l := end - 1; l >= start; l-- {
+		closing := strings.LastIndexByte(r.lines[l], ']')
+		if closing < 0 {
+			continue
+		}
+		before := strings.TrimRight(r.lines[l][:closing], " ")
+		separator := ", "
+		if strings.HasSuffix(before, "[") {
+			separator = ""
+		}
+		r.lines[l] = before + separator + item + r.lines[l][closing:]
+		return
+	}
+}
+
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+func gitlabIncludesFile(include *yaml.Node, file string) bool {
+	switch include.Kind {
+	case yaml.ScalarNode:
+		return strings.TrimPrefix(include.Value, "/") == file
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(include.Content); i += 2 {
+			if include.Content[i].Value == "local" && strings.TrimPrefix(include.Content[i+1].Value, "/") == file {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package executors
+
+import "testing"
+
+func TestAddGitlabInclude(t *testing.T) {
+	stages := []string{"lint", "test", "build", "deploy"}
+	tests := []struct {
+		name   string
+		root   string
+		stages []string
+		want   string
+	}{
+		{
+			name: "no include",
+			root: "stages:\n  - build\nbuild:\n  script: make\n",
+			want: "include:\n  - local: ci/autoflow.yml\nstages:\n  - lint\n  - test\n  - build\n  - deploy\nbuild:\n  script: make\n",
+		},
+		{
+			name: "no include after leading comments",
+			root: "# Pipeline.\n# Owned by the platform team.\nstages: [build]\nbuild:\n  script: make\n",
+			want: "# Pipeline.\n# Owned by the platform team.\ninclude:\n  - local: ci/autoflow.yml\nstages: [lint, test, build, deploy]\nbuild:\n  script: make\n",
+		},
+		{
+			name: "string",
+			root: "include: templates/base.yml\nstages: [build]\n",
+			want: "include:\n  - templates/base.yml\n  - local: ci/autoflow.yml\nstages: [lint, test, build, deploy]\n",
+		},
+		{
+			name: "local map on the key line",
+			root: "include: {local: templates/base.yml}\nstages: [build]\n",
+			want: "include:\n  - {local: templates/base.yml}\n  - local: ci/autoflow.yml\nstages: [lint, test, build, deploy]\n",
+		},
+		{
+			name: "block mapping",
+			root: "include:\n  project: group/templates\n  file: base.yml\nstages: [build]\n",
+			want: "include:\n  - project: group/templates\n    file: base.yml\n  - local: ci/autoflow.yml\nstages: [lint, test, build, deploy]\n",
+		},
+		{
+			name: "block list",
+			root: "include:\n  - local: templates/base.yml\n  # Shared jobs.\n  - template: Jobs/SAST.gitlab-ci.yml\n\n# Stages.\nstages: [build]\n",
+			want: "include:\n  - local: templates/base.yml\n  # Shared jobs.\n  - template: Jobs/SAST.gitlab-ci.yml\n  - local: ci/autoflow.yml\n\n# Stages.\nstages: [lint, test, build, deploy]\n",
+		},
+		{
+			name: "flow list",
+			root: "include: [templates/base.yml, {template: Jobs/SAST.gitlab-ci.yml}]\nstages: [build]\n",
+			want: "include: [templates/base.yml, {template: Jobs/SAST.gitlab-ci.yml}, {local: ci/autoflow.yml}]\nstages: [lint, test, build, deploy]\n",
+		},
+		{
+			name: "empty flow list",
+			root: "include: []\nstages: [build]\n",
+			want: "include: [{local: ci/autoflow.yml}]\nstages: [lint, test, build, deploy]\n",
+		},
+		{
+			name: "already included as a string",
+			root: "include: /ci/autoflow.yml\nstages: [lint, test, build, deploy]\n",
+			want: "include: /ci/autoflow.yml\nstages: [lint, test, build, deploy]\n",
+		},
+		{
+			name: "already included as a local entry",
+			root: "include:\n  - local: ci/autoflow.yml\nstages:\n  - lint\n  - test\n  - build\n  - deploy\n",
+			want: "include:\n  - local: ci/autoflow.yml\nstages:\n  - lint\n  - test\n  - build\n  - deploy\n",
+		},
+		{
+			name:   "missing stages",
+			root:   "build:\n  script: make\n",
+			stages: []string{"lint", "build"},
+			want:   "include:\n  - local: ci/autoflow.yml\nstages:\n  - lint\n  - test\n  - build\n  - deploy\nbuild:\n  script: make\n",
+		},
+		{
+			name: "missing stages after an include",
+			root: "# Pipeline.\ninclude: templates/base.yml\n\nbuild:\n  script: make\n",
+			want: "# Pipeline.\ninclude:\n  - templates/base.yml\n  - local: ci/autoflow.yml\nstages:\n  - lint\n  - test\n  - build\n  - deploy\n\nbuild:\n  script: make\n",
+		},
+		{
+			name: "block stages keep their order and comments",
+			// Deploy has no declared stage after it, so it goes last.
+			root: "stages:\n  # Checks first.\n  - prepare\n  - build\n  - release # Tagged only.\n",
+			want: "include:\n  - local: ci/autoflow.yml\nstages:\n  # Checks first.\n  - prepare\n  - lint\n  - test\n  - build\n  - release # Tagged only.\n  - deploy\n",
+		},
+		{
+			name: "anchors survive",
+			root: ".defaults: &defaults\n  tags: [docker]\n\nbuild:\n  <<: *defaults\n  script: make\n",
+			want: "include:\n  - local: ci/autoflow.yml\nstages:\n  - lint\n  - test\n  - build\n  - deploy\n.defaults: &defaults\n  tags: [docker]\n\nbuild:\n  <<: *defaults\n  script: make\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			add := test.stages
+			if add == nil {
+				add = stages
+			}
+			got, err := addGitlabInclude([]byte(test.root), "ci/autoflow.yml", add)
+			if err != nil {
+				t.Fatalf("addGitlabInclude() error = %v", err)
+			}
+			if string(got) != test.want {
+				t.Errorf("addGitlabInclude() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAddGitlabIncludeInvalid(t *testing.T) {
+	for _, root := range []string{"- build\n", "build: [\n"} {
+		if _, err := addGitlabInclude([]byte(root), "ci/autoflow.yml", []string{"build"}); err == nil {
+			t.Errorf("addGitlabInclude(%q) succeeded, want an error", root)
+		}
+	}
+}
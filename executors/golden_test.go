@@ -0,0 +1,92 @@
+package executors
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares output with testdata/<name>.golden.
+func checkGolden(t *testing.T, name, output string) {
+	t.Helper()
+	file := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(output), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if output != string(want) {
+		t.Errorf("output differs from %s, rerun with -update to review the change:\n%s", file, output)
+	}
+}
+
+// testNodeResult is a pnpm project with a script for every phase.
+func testNodeResult() *registry.ExtractorResult {
+	return &registry.ExtractorResult{
+		Runtime:               "node",
+		RuntimeVersion:        "22",
+		RuntimeVersions:       []string{"20", "22"},
+		Image:                 "node:22-alpine",
+		PackageManager:        "pnpm",
+		PackageManagerVersion: "9.12.0",
+		Lockfile:              "pnpm-lock.yaml",
+		Scripts: map[string]string{
+			"Install": "pnpm install --frozen-lockfile",
+			"Lint":    "pnpm run lint",
+			"Test":    "pnpm run test",
+			"Build":   "pnpm run build",
+			"Deploy":  "pnpm run deploy",
+		},
+		Artifacts: []string{"dist/"},
+		TestReport: &registry.TestReport{
+			Script: "pnpm run test -- --reporter=junit --outputFile=report.xml",
+			JUnit:  "report.xml",
+		},
+		Services: []registry.Service{{
+			Name:  "postgres",
+			Image: "postgres:16",
+			Ports: []registry.ServicePort{{Host: 5432, Container: 5432}},
+			Env:   map[string]string{"POSTGRES_PASSWORD": "postgres"},
+		}},
+		Env: []registry.EnvVar{{Name: "API_TOKEN", Source: ".env.example"}},
+	}
+}
+
+// testPythonResult is a pip project without a version matrix.
+func testPythonResult() *registry.ExtractorResult {
+	return &registry.ExtractorResult{
+		Runtime:        "python",
+		RuntimeVersion: "3.12",
+		Image:          "python:3.12-slim",
+		PackageManager: "pip",
+		Lockfile:       "requirements.txt",
+		Scripts: map[string]string{
+			"Install": "pip install -r requirements.txt",
+			"Lint":    "ruff check .",
+			"Test":    "pytest",
+		},
+	}
+}
+
+func generate(t *testing.T, executor registry.Executor, result *registry.ExtractorResult, opts *registry.Options) string {
+	t.Helper()
+	output, err := executor.Generate(result, t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	return output
+}
@@ -0,0 +1,59 @@
+package executors
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/zraisan/AutoFlow/actionlock"
+	"gopkg.in/yaml.v3"
+)
+
+// pinGithubActions rewrites every action reference to the commit recorded in
+// the project's lock file. It returns the original refs keyed by the pinned
+// reference, and leaves the workflow untouched when the project has no lock file.
+func pinGithubActions(workflow *githubWorkflow, path string) (map[string]string, error) {
+	lock, err := actionlock.Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	for _, job := range workflow.Jobs {
+		for i, step := range job.Steps {
+			if step.Uses == "" {
+				continue
+			}
+			pinned, version, err := lock.Pin(step.Uses)
+			if err != nil {
+				return nil, err
+			}
+			job.Steps[i].Uses = pinned
+			if version != "" {
+				versions[pinned] = version
+			}
+		}
+	}
+	return versions, nil
+}
+
+// annotateActionVersions adds the original ref as a line comment after every
+// pinned "uses" value.
+func annotateActionVersions(node *yaml.Node, versions map[string]string) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value != "uses" || value.Kind != yaml.ScalarNode {
+				continue
+			}
+			if version, ok := versions[value.Value]; ok {
+				value.LineComment = version
+			}
+		}
+	}
+	for _, child := range node.Content {
+		annotateActionVersions(child, versions)
+	}
+}
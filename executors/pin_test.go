@@ -0,0 +1,59 @@
+package executors
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zraisan/AutoFlow/actionlock"
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+func TestGithubPinnedActions(t *testing.T) {
+	const sha = "11bd71901bbe5b1630ceea73d27597364c9af683"
+	path := t.TempDir()
+	opts := &registry.Options{Name: "ci"}
+	if _, err := (&GithubExecutor{}).Generate(testPythonResult(), path, opts); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	refs, err := actionlock.Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every action but the last one is locked.
+	lock := &actionlock.File{Actions: make(map[string]string)}
+	for _, ref := range refs[:len(refs)-1] {
+		lock.Actions[ref] = sha
+	}
+	if err := lock.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&GithubExecutor{}).Generate(testPythonResult(), path, opts); err == nil || !strings.Contains(err.Error(), refs[len(refs)-1]) {
+		t.Fatalf("Generate() error = %v, want one naming %s", err, refs[len(refs)-1])
+	}
+
+	lock.Actions[refs[len(refs)-1]] = sha
+	if err := lock.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	output, err := (&GithubExecutor{}).Generate(testPythonResult(), path, opts)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, ref := range refs {
+		action, version, _ := strings.Cut(ref, "@")
+		if !strings.Contains(output, "uses: "+action+"@"+sha+" # "+version+"\n") {
+			t.Errorf("output does not pin %s:\n%s", ref, output)
+		}
+	}
+
+	// The refs kept in the comments are found again on the next lock run.
+	pinned, err := actionlock.Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(pinned, refs) {
+		t.Errorf("Scan() of the pinned workflow = %q, want %q", pinned, refs)
+	}
+}
@@ -0,0 +1,78 @@
+package executors
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+var lifecyclePhases = []string{"install", "lint", "test", "build", "deploy"}
+
+func scriptPhase(key string) string {
+	switch {
+	case key == "Install":
+		return "install"
+	case strings.HasPrefix(key, "Test"):
+		return "test"
+	case key == "Build":
+		return "build"
+	case key == "Deploy":
+		return "deploy"
+	}
+	return "lint"
+}
+
+// orderedScripts returns the script keys sorted by lifecycle phase, and
+// alphabetically within a phase, so generated pipelines are stable.
+func orderedScripts(scripts map[string]string) []string {
+	keys := make([]string, 0, len(scripts))
+	for key := range scripts {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		pa := slices.Index(lifecyclePhases, scriptPhase(a))
+		pb := slices.Index(lifecyclePhases, scriptPhase(b))
+		if pa != pb {
+			return cmp.Compare(pa, pb)
+		}
+		return strings.Compare(a, b)
+	})
+	return keys
+}
+
+// packageManagerSetup returns the commands that make the package manager
+// available in the runtime image, since Node.js images only ship npm.
+// Corepack picks up the version pinned in package.json by itself.
+func packageManagerSetup(result *registry.ExtractorResult) []string {
+	switch result.PackageManager {
+	case "pnpm", "yarn":
+		return []string{"corepack enable"}
+	case "bun":
+		if result.PackageManagerVersion != "" {
+			return []string{"npm install -g bun@" + result.PackageManagerVersion}
+		}
+		return []string{"npm install -g bun"}
+	}
+	return nil
+}
+
+// installCommands returns the commands that prepare a fresh container for the
+// project's scripts: the package manager setup followed by the Install script.
+func installCommands(result *registry.ExtractorResult) []string {
+	commands := packageManagerSetup(result)
+	if install := result.Scripts["Install"]; install != "" {
+		commands = append(commands, install)
+	}
+	return commands
+}
+
+// artifactGlob turns a directory artifact such as "dist/" into a glob that
+// matches every file below it.
+func artifactGlob(artifact string) string {
+	if dir, found := strings.CutSuffix(artifact, "/"); found {
+		return dir + "/**/*"
+	}
+	return artifact
+}
@@ -0,0 +1,27 @@
+package executors
+
+import "github.com/zraisan/AutoFlow/registry"
+
+// RequiredSecrets returns the environment variables the generated pipelines
+// expect to be configured as secrets. Variables that point at a service
+// container are set by the pipeline itself and left out.
+func RequiredSecrets(result *registry.ExtractorResult) []registry.EnvVar {
+	provided := serviceEnv(result.Services, true)
+
+	var secrets []registry.EnvVar
+	for _, variable := range result.Env {
+		if _, ok := provided[variable.Name]; !ok {
+			secrets = append(secrets, variable)
+		}
+	}
+	return secrets
+}
+
+// secretDescription explains where a secret comes from, for platforms that
+// show variable descriptions.
+func secretDescription(variable registry.EnvVar) string {
+	if variable.Description != "" {
+		return variable.Description
+	}
+	return "Required by " + variable.Source
+}
@@ -0,0 +1,117 @@
+package executors
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+// serviceKinds maps well-known service images to the variable the tests read
+// the connection URL from and the port the service listens on.
+var serviceKinds = map[string]struct {
+	variable string
+	port     int
+}{
+	"postgres": {variable: "DATABASE_URL", port: 5432},
+	"postgis":  {variable: "DATABASE_URL", port: 5432},
+	"mysql":    {variable: "DATABASE_URL", port: 3306},
+	"mariadb":  {variable: "DATABASE_URL", port: 3306},
+	"redis":    {variable: "REDIS_URL", port: 6379},
+	"valkey":   {variable: "REDIS_URL", port: 6379},
+	"mongo":    {variable: "MONGODB_URL", port: 27017},
+	"rabbitmq": {variable: "AMQP_URL", port: 5672},
+}
+
+// serviceKind returns the image name without registry, namespace and tag,
+// e.g. "postgres" for "docker.io/library/postgres:16-alpine".
+func serviceKind(image string) string {
+	name, _, _ := strings.Cut(path.Base(image), ":")
+	name, _, _ = strings.Cut(name, "@")
+	return name
+}
+
+// servicePort returns the port a service is reached on. Local services are
+// reached through the host port mapped to the service's well-known port,
+// others directly on the container port.
+func servicePort(service registry.Service, local bool) int {
+	kind, ok := serviceKinds[serviceKind(service.Image)]
+	if !ok {
+		return 0
+	}
+	if local {
+		for _, port := range service.Ports {
+			if port.Container == kind.port {
+				return port.Host
+			}
+		}
+	}
+	return kind.port
+}
+
+// serviceEnv returns the connection variables for the services the tests
+// use. local services run on localhost, as on GitHub runners; otherwise each
+// service is reached by its name, as with GitLab service aliases.
+func serviceEnv(services []registry.Service, local bool) map[string]string {
+	env := make(map[string]string)
+	for _, service := range services {
+		kind, ok := serviceKinds[serviceKind(service.Image)]
+		if !ok {
+			continue
+		}
+		if _, taken := env[kind.variable]; taken {
+			continue
+		}
+
+		host := service.Name
+		if local {
+			host = "localhost"
+		}
+		address := fmt.Sprintf("%s:%d", host, servicePort(service, local))
+
+		switch serviceKind(service.Image) {
+		case "postgres", "postgis":
+			user := serviceEnvOr(service, "postgres", "POSTGRES_USER")
+			password := serviceEnvOr(service, "", "POSTGRES_PASSWORD")
+			database := serviceEnvOr(service, user, "POSTGRES_DB")
+			env[kind.variable] = fmt.Sprintf("postgres://%s@%s/%s", serviceCredentials(user, password), address, database)
+		case "mysql", "mariadb":
+			user := serviceEnvOr(service, "root", "MYSQL_USER", "MARIADB_USER")
+			password := serviceEnvOr(service, "", "MYSQL_PASSWORD", "MARIADB_PASSWORD")
+			if user == "root" {
+				password = serviceEnvOr(service, "", "MYSQL_ROOT_PASSWORD", "MARIADB_ROOT_PASSWORD")
+			}
+			database := serviceEnvOr(service, "", "MYSQL_DATABASE", "MARIADB_DATABASE")
+			env[kind.variable] = fmt.Sprintf("mysql://%s@%s/%s", serviceCredentials(user, password), address, database)
+		case "redis", "valkey":
+			env[kind.variable] = "redis://" + address
+		case "mongo":
+			env[kind.variable] = "mongodb://" + address
+		case "rabbitmq":
+			user := serviceEnvOr(service, "guest", "RABBITMQ_DEFAULT_USER")
+			password := serviceEnvOr(service, "guest", "RABBITMQ_DEFAULT_PASS")
+			env[kind.variable] = fmt.Sprintf("amqp://%s@%s", serviceCredentials(user, password), address)
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
+
+func serviceEnvOr(service registry.Service, fallback string, names ...string) string {
+	for _, name := range names {
+		if value := service.Env[name]; value != "" {
+			return value
+		}
+	}
+	return fallback
+}
+
+func serviceCredentials(user, password string) string {
+	if password == "" {
+		return user
+	}
+	return user + ":" + password
+}
@@ -0,0 +1,259 @@
+package executors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	registry.RegisterExecutor(&TektonExecutor{})
+}
+
+const tektonAPIVersion = "tekton.dev/v1"
+
+var tektonInvalidName = regexp.MustCompile(`[^a-z0-9-]+`)
+
+type TektonExecutor struct{}
+
+type tektonMetadata struct {
+	Name         string `yaml:"name,omitempty"`
+	GenerateName string `yaml:"generateName,omitempty"`
+}
+
+type tektonTask struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   tektonMetadata `yaml:"metadata"`
+	Spec       tektonTaskSpec `yaml:"spec"`
+}
+
+type tektonTaskSpec struct {
+	Workspaces []tektonWorkspace `yaml:"workspaces"`
+	Steps      []tektonStep      `yaml:"steps"`
+}
+
+type tektonStep struct {
+	Name       string `yaml:"name"`
+	Image      string `yaml:"image"`
+	WorkingDir string `yaml:"workingDir"`
+	Script     string `yaml:"script"`
+}
+
+type tektonWorkspace struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type tektonPipeline struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   tektonMetadata     `yaml:"metadata"`
+	Spec       tektonPipelineSpec `yaml:"spec"`
+}
+
+type tektonPipelineSpec struct {
+	Params     []tektonParamSpec    `yaml:"params"`
+	Workspaces []tektonWorkspace    `yaml:"workspaces"`
+	Tasks      []tektonPipelineTask `yaml:"tasks"`
+}
+
+type tektonParamSpec struct {
+	Name        string `yaml:"name"`
+	Type        string `yaml:"type"`
+	Description string `yaml:"description,omitempty"`
+	Default     string `yaml:"default,omitempty"`
+}
+
+type tektonPipelineTask struct {
+	Name       string                   `yaml:"name"`
+	TaskRef    tektonTaskRef            `yaml:"taskRef"`
+	RunAfter   []string                 `yaml:"runAfter,omitempty"`
+	Params     []tektonParam            `yaml:"params,omitempty"`
+	Workspaces []tektonWorkspaceBinding `yaml:"workspaces"`
+}
+
+type tektonTaskRef struct {
+	Name     string        `yaml:"name,omitempty"`
+	Resolver string        `yaml:"resolver,omitempty"`
+	Params   []tektonParam `yaml:"params,omitempty"`
+}
+
+type tektonParam struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+type tektonWorkspaceBinding struct {
+	Name      string `yaml:"name"`
+	Workspace string `yaml:"workspace"`
+}
+
+type tektonPipelineRun struct {
+	APIVersion string                `yaml:"apiVersion"`
+	Kind       string                `yaml:"kind"`
+	Metadata   tektonMetadata        `yaml:"metadata"`
+	Spec       tektonPipelineRunSpec `yaml:"spec"`
+}
+
+type tektonPipelineRunSpec struct {
+	PipelineRef tektonTaskRef           `yaml:"pipelineRef"`
+	Params      []tektonParam           `yaml:"params"`
+	Workspaces  []tektonWorkspaceVolume `yaml:"workspaces"`
+}
+
+type tektonWorkspaceVolume struct {
+	Name                string                    `yaml:"name"`
+	VolumeClaimTemplate tektonVolumeClaimTemplate `yaml:"volumeClaimTemplate"`
+}
+
+type tektonVolumeClaimTemplate struct {
+	Spec tektonVolumeClaimSpec `yaml:"spec"`
+}
+
+type tektonVolumeClaimSpec struct {
+	AccessModes []string `yaml:"accessModes"`
+	Resources   struct {
+		Requests map[string]string `yaml:"requests"`
+	} `yaml:"resources"`
+}
+
+func (t *TektonExecutor) Name() string {
+	return "Tekton"
+}
+
+func (t *TektonExecutor) Generate(result *registry.ExtractorResult, path string, opts *registry.Options) (string, error) {
+	name := opts.Name
+	if len(name) == 0 {
+		name = "ci"
+	}
+	pipelineName := tektonName(name)
+	taskName := pipelineName + "-scripts"
+	revision := defaultBranch(path)
+
+	// Each step runs in its own container and only the workspace carries
+	// over, so each step installs the dependencies before its script.
+	var steps []tektonStep
+	for _, key := range orderedScripts(result.Scripts) {
+		if key == "Install" {
+			continue
+		}
+		commands := append(installCommands(result), result.Scripts[key])
+		steps = append(steps, tektonStep{
+			Name:       tektonName(key),
+			Image:      result.Image,
+			WorkingDir: "$(workspaces.source.path)",
+			Script:     "#!/usr/bin/env sh\nset -e\n" + strings.Join(commands, "\n") + "\n",
+		})
+	}
+
+	task := tektonTask{
+		APIVersion: tektonAPIVersion,
+		Kind:       "Task",
+		Metadata:   tektonMetadata{Name: taskName},
+		Spec: tektonTaskSpec{
+			Workspaces: []tektonWorkspace{
+				{Name: "source", Description: "The checked out project sources."},
+			},
+			Steps: steps,
+		},
+	}
+
+	pipeline := tektonPipeline{
+		APIVersion: tektonAPIVersion,
+		Kind:       "Pipeline",
+		Metadata:   tektonMetadata{Name: pipelineName},
+		Spec: tektonPipelineSpec{
+			Params: []tektonParamSpec{
+				{Name: "repo-url", Type: "string", Description: "The git repository to build."},
+				{Name: "revision", Type: "string", Description: "The git revision to build.", Default: revision},
+			},
+			Workspaces: []tektonWorkspace{
+				{Name: "shared-data"},
+			},
+			Tasks: []tektonPipelineTask{
+				{
+					Name: "fetch-source",
+					TaskRef: tektonTaskRef{
+						Resolver: "git",
+						Params: []tektonParam{
+							{Name: "url", Value: "https://github.com/tektoncd/catalog.git"},
+							{Name: "revision", Value: "main"},
+							{Name: "pathInRepo", Value: "task/git-clone/0.9/git-clone.yaml"},
+						},
+					},
+					Params: []tektonParam{
+						{Name: "url", Value: "$(params.repo-url)"},
+						{Name: "revision", Value: "$(params.revision)"},
+					},
+					Workspaces: []tektonWorkspaceBinding{
+						{Name: "output", Workspace: "shared-data"},
+					},
+				},
+				{
+					Name:     "run-scripts",
+					TaskRef:  tektonTaskRef{Name: taskName},
+					RunAfter: []string{"fetch-source"},
+					Workspaces: []tektonWorkspaceBinding{
+						{Name: "source", Workspace: "shared-data"},
+					},
+				},
+			},
+		},
+	}
+
+	run := tektonPipelineRun{
+		APIVersion: tektonAPIVersion,
+		Kind:       "PipelineRun",
+		Metadata:   tektonMetadata{GenerateName: pipelineName + "-run-"},
+		Spec: tektonPipelineRunSpec{
+			PipelineRef: tektonTaskRef{Name: pipelineName},
+			Params: []tektonParam{
+				{Name: "repo-url", Value: "https://example.com/your/repository.git"},
+				{Name: "revision", Value: revision},
+			},
+		},
+	}
+	volume := tektonWorkspaceVolume{Name: "shared-data"}
+	volume.VolumeClaimTemplate.Spec.AccessModes = []string{"ReadWriteOnce"}
+	volume.VolumeClaimTemplate.Spec.Resources.Requests = map[string]string{"storage": "1Gi"}
+	run.Spec.Workspaces = []tektonWorkspaceVolume{volume}
+
+	manifestDir := filepath.Join(path, ".tekton")
+	if err := os.MkdirAll(manifestDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	manifests := []struct {
+		file     string
+		resource any
+	}{
+		{fmt.Sprintf("%s-task.yaml", name), &task},
+		{fmt.Sprintf("%s-pipeline.yaml", name), &pipeline},
+		{fmt.Sprintf("%s-pipelinerun.yaml", name), &run},
+	}
+
+	var documents []string
+	for _, manifest := range manifests {
+		data, err := yaml.Marshal(manifest.resource)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal %s: %w", manifest.file, err)
+		}
+		if err := os.WriteFile(filepath.Join(manifestDir, manifest.file), data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", manifest.file, err)
+		}
+		documents = append(documents, string(data))
+	}
+
+	return strings.Join(documents, "---\n"), nil
+}
+
+func tektonName(name string) string {
+	name = tektonInvalidName.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(name, "-")
+}
@@ -0,0 +1,44 @@
+package executors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+func TestTektonGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		result *registry.ExtractorResult
+	}{
+		{"node", testNodeResult()},
+		{"python", testPythonResult()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := generate(t, &TektonExecutor{}, test.result, &registry.Options{Name: "ci"})
+			checkGolden(t, "tekton/"+test.name, output)
+		})
+	}
+}
+
+func TestTektonRevision(t *testing.T) {
+	path := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(path, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, ".git", "HEAD"), []byte("ref: refs/heads/develop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := (&TektonExecutor{}).Generate(testPythonResult(), path, &registry.Options{Name: "ci"})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.Contains(output, "default: develop") || !strings.Contains(output, "value: develop") {
+		t.Errorf("revision does not default to the checked out branch:\n%s", output)
+	}
+}
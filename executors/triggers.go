@@ -0,0 +1,118 @@
+package executors
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+// triggersOrDefault falls back to pushes on the default branch when no
+// trigger was selected.
+func triggersOrDefault(triggers registry.Triggers) registry.Triggers {
+	if !triggers.Push && !triggers.PullRequest && len(triggers.Tags) == 0 && triggers.Schedule == "" && !triggers.Dispatch {
+		triggers.Push = true
+	}
+	return triggers
+}
+
+func triggerBranches(triggers registry.Triggers, path string) []string {
+	if len(triggers.Branches) > 0 {
+		return triggers.Branches
+	}
+	return []string{defaultBranch(path)}
+}
+
+// defaultBranch reads the branch origin/HEAD points at from the repository
+// containing path, falling back to the checked out branch and then "main".
+func defaultBranch(path string) string {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "main"
+	}
+
+	for {
+		gitDir := filepath.Join(dir, ".git")
+		if info, err := os.Stat(gitDir); err == nil {
+			if !info.IsDir() {
+				gitDir = resolveGitDir(dir, gitDir)
+			}
+			commonDir := gitDir
+			if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+				commonDir = filepath.Join(gitDir, strings.TrimSpace(string(data)))
+			}
+
+			if branch, found := readSymbolicRef(filepath.Join(commonDir, "refs/remotes/origin/HEAD"), "refs/remotes/origin/"); found {
+				return branch
+			}
+			if branch, found := readSymbolicRef(filepath.Join(gitDir, "HEAD"), "refs/heads/"); found {
+				return branch
+			}
+			return "main"
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "main"
+		}
+		dir = parent
+	}
+}
+
+// resolveGitDir follows the "gitdir:" pointer a worktree or submodule keeps
+// in place of a .git directory.
+func resolveGitDir(dir, file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return file
+	}
+	target, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !found {
+		return file
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	return target
+}
+
+func readSymbolicRef(file, prefix string) (string, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", false
+	}
+	ref, found := strings.CutPrefix(strings.TrimSpace(string(data)), "ref:")
+	if !found {
+		return "", false
+	}
+	return strings.CutPrefix(strings.TrimSpace(ref), prefix)
+}
+
+// globToRegexp converts a branch or tag glob such as "release/*" into the
+// regular expression syntax used by GitLab rules.
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	sb.WriteString("/^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString(".")
+		case '/':
+			sb.WriteString(`\/`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$/")
+	return sb.String()
+}
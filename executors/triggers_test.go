@@ -0,0 +1,69 @@
+package executors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob string
+		want string
+	}{
+		{"main", "/^main$/"},
+		{"v*", "/^v[^/]*$/"},
+		{"release/*", `/^release\/[^/]*$/`},
+		{"feature/**", `/^feature\/.*$/`},
+		{"v1.?", `/^v1\..$/`},
+	}
+	for _, test := range tests {
+		if got := globToRegexp(test.glob); got != test.want {
+			t.Errorf("globToRegexp(%q) = %q, want %q", test.glob, got, test.want)
+		}
+	}
+}
+
+func TestDefaultBranch(t *testing.T) {
+	write := func(t *testing.T, file, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("origin HEAD", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/feature\n")
+		write(t, filepath.Join(dir, ".git", "refs", "remotes", "origin", "HEAD"), "ref: refs/remotes/origin/trunk\n")
+		if got := defaultBranch(filepath.Join(dir, "sub")); got != "trunk" {
+			t.Errorf("defaultBranch() = %q, want trunk", got)
+		}
+	})
+	t.Run("checked out branch", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, filepath.Join(dir, ".git", "HEAD"), "ref: refs/heads/develop\n")
+		if got := defaultBranch(dir); got != "develop" {
+			t.Errorf("defaultBranch() = %q, want develop", got)
+		}
+	})
+	t.Run("no repository", func(t *testing.T) {
+		if got := defaultBranch(t.TempDir()); got != "main" {
+			t.Errorf("defaultBranch() = %q, want main", got)
+		}
+	})
+}
+
+func TestTriggersOrDefault(t *testing.T) {
+	if got := triggersOrDefault(registry.Triggers{}); !got.Push {
+		t.Errorf("triggersOrDefault() without triggers = %+v, want pushes", got)
+	}
+	if got := triggersOrDefault(registry.Triggers{Schedule: "0 3 * * 1"}); got.Push {
+		t.Errorf("triggersOrDefault() with a schedule = %+v, want no pushes", got)
+	}
+}
@@ -0,0 +1,178 @@
+package extractors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+	"gopkg.in/yaml.v3"
+)
+
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+// composeDefault matches ${VAR:-default} and ${VAR-default} interpolations.
+var composeDefault = regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_]*:?-([^}]*)\}`)
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string              `yaml:"image"`
+	Ports       []composePort       `yaml:"ports"`
+	Environment composeEnvironment  `yaml:"environment"`
+	Healthcheck *composeHealthcheck `yaml:"healthcheck"`
+}
+
+// composePort accepts both the short "host:container" syntax and the long
+// syntax with target and published keys.
+type composePort struct {
+	Host      string
+	Container string
+}
+
+func (p *composePort) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Target    string `yaml:"target"`
+			Published string `yaml:"published"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return err
+		}
+		p.Host, p.Container = long.Published, long.Target
+		return nil
+	}
+
+	spec, _, _ := strings.Cut(node.Value, "/")
+	parts := strings.Split(spec, ":")
+	p.Container = parts[len(parts)-1]
+	if len(parts) > 1 {
+		p.Host = parts[len(parts)-2]
+	}
+	return nil
+}
+
+// composeEnvironment accepts both the mapping and the KEY=value list syntax.
+type composeEnvironment map[string]string
+
+func (e *composeEnvironment) UnmarshalYAML(node *yaml.Node) error {
+	*e = make(composeEnvironment)
+	if node.Kind == yaml.MappingNode {
+		return node.Decode((*map[string]string)(e))
+	}
+
+	var entries []string
+	if err := node.Decode(&entries); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name, value, _ := strings.Cut(entry, "=")
+		(*e)[name] = value
+	}
+	return nil
+}
+
+type composeHealthcheck struct {
+	Test     composeCommand `yaml:"test"`
+	Interval string         `yaml:"interval"`
+	Timeout  string         `yaml:"timeout"`
+	Retries  int            `yaml:"retries"`
+}
+
+// composeCommand accepts a health check test as a shell string or as a
+// ["CMD", ...] or ["CMD-SHELL", "..."] list.
+type composeCommand string
+
+func (c *composeCommand) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = composeCommand(node.Value)
+		return nil
+	}
+
+	var args []string
+	if err := node.Decode(&args); err != nil {
+		return err
+	}
+	if len(args) == 0 || args[0] == "NONE" {
+		return nil
+	}
+	if args[0] == "CMD" || args[0] == "CMD-SHELL" {
+		args = args[1:]
+	}
+	*c = composeCommand(strings.Join(args, " "))
+	return nil
+}
+
+// detectComposeServices returns the image-based services of the project's
+// compose file. Services that are built from source are the application
+// itself and are left out.
+func detectComposeServices(path string) ([]registry.Service, error) {
+	name := registry.FirstExisting(path, composeFiles...)
+	if name == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(path, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	var compose composeFile
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	var services []registry.Service
+	for serviceName, definition := range compose.Services {
+		if definition.Image == "" {
+			continue
+		}
+
+		service := registry.Service{
+			Name:  serviceName,
+			Image: composeInterpolate(definition.Image),
+		}
+		for _, port := range definition.Ports {
+			container, err := strconv.Atoi(composeInterpolate(port.Container))
+			if err != nil {
+				continue
+			}
+			host, err := strconv.Atoi(composeInterpolate(port.Host))
+			if err != nil {
+				host = container
+			}
+			service.Ports = append(service.Ports, registry.ServicePort{Host: host, Container: container})
+		}
+		for key, value := range definition.Environment {
+			if service.Env == nil {
+				service.Env = make(map[string]string)
+			}
+			service.Env[key] = composeInterpolate(value)
+		}
+		if check := definition.Healthcheck; check != nil && check.Test != "" {
+			service.HealthCheck = &registry.HealthCheck{
+				Command:  strings.ReplaceAll(string(check.Test), "$$", "$"),
+				Interval: check.Interval,
+				Timeout:  check.Timeout,
+				Retries:  check.Retries,
+			}
+		}
+		services = append(services, service)
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+	return services, nil
+}
+
+// composeInterpolate replaces variables that have a default value with that
+// default, since the variables are not set in CI.
+func composeInterpolate(value string) string {
+	return composeDefault.ReplaceAllString(value, "$1")
+}
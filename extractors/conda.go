@@ -0,0 +1,92 @@
+package extractors
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+	"gopkg.in/yaml.v3"
+)
+
+var condaEnvironmentFiles = []string{"environment.yml", "environment.yaml"}
+
+// condaDefaultEnvironment names the environment when the file leaves the
+// name out.
+const condaDefaultEnvironment = "ci"
+
+// condaEnvironment is a conda or mamba environment file. Dependencies are
+// either match specs such as "python=3.11" or a pip table with requirements.
+type condaEnvironment struct {
+	File         string `yaml:"-"`
+	Name         string `yaml:"name"`
+	Dependencies []any  `yaml:"dependencies"`
+}
+
+func readCondaEnvironment(path string) (*condaEnvironment, error) {
+	name := registry.FirstExisting(path, condaEnvironmentFiles...)
+	if name == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(path, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	env := &condaEnvironment{File: name}
+	if err := yaml.Unmarshal(data, env); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if env.Name == "" {
+		env.Name = condaDefaultEnvironment
+	}
+	return env, nil
+}
+
+// packages returns the normalized names of the conda and pip packages.
+func (e *condaEnvironment) packages() []string {
+	if e == nil {
+		return nil
+	}
+
+	var names []string
+	for _, dependency := range e.Dependencies {
+		switch dependency := dependency.(type) {
+		case string:
+			// Drop the channel of specs such as "conda-forge::numpy".
+			if _, spec, found := strings.Cut(dependency, "::"); found {
+				dependency = spec
+			}
+			names = append(names, pythonPackageName(dependency))
+		case map[string]any:
+			pip, _ := dependency["pip"].([]any)
+			for _, requirement := range pip {
+				if requirement, ok := requirement.(string); ok {
+					names = append(names, pythonPackageName(requirement))
+				}
+			}
+		}
+	}
+	return names
+}
+
+// pythonVersion returns the version of a "python=3.11" or "python==3.11.*"
+// spec, or an empty string when python is not pinned.
+func (e *condaEnvironment) pythonVersion() string {
+	if e == nil {
+		return ""
+	}
+	for _, dependency := range e.Dependencies {
+		spec, ok := dependency.(string)
+		if !ok {
+			continue
+		}
+		version, found := strings.CutPrefix(strings.ReplaceAll(spec, " ", ""), "python=")
+		version = strings.TrimSuffix(strings.TrimPrefix(version, "="), ".*")
+		if found && pythonVersionPin.MatchString(version) {
+			return version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,45 @@
+package extractors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadCondaEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	data := "name: science\ndependencies:\n  - python=3.11\n  - numpy\n  - pip:\n      - pytest\n"
+	if err := os.WriteFile(filepath.Join(dir, "environment.yml"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&PythonExtractor{}).Extract(dir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if result.PackageManager != "conda" || result.Environment != "science" || result.Lockfile != "environment.yml" {
+		t.Errorf("Extract() = %q, %q, %q, want conda with the science environment from environment.yml", result.PackageManager, result.Environment, result.Lockfile)
+	}
+}
+
+func TestReadCondaEnvironmentInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "environment.yml"), []byte("name: [science\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("pytest\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&PythonExtractor{}).Extract(dir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "environment.yml") {
+		t.Errorf("Warnings = %q, want one warning about environment.yml", result.Warnings)
+	}
+	if result.PackageManager != "pip" {
+		t.Errorf("PackageManager = %q, want pip without the conda environment", result.PackageManager)
+	}
+}
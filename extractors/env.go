@@ -0,0 +1,181 @@
+package extractors
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+var envExampleFiles = []string{".env.example", ".env.sample", ".env.template", ".env.dist"}
+
+// envReferences matches reads of a variable per source file extension. The
+// second group is set when the read falls back to a default, which makes the
+// variable optional. os.LookupEnv is left out, since its caller handles the
+// variable being unset.
+var envReferences = map[string][]*regexp.Regexp{
+	".go": {
+		regexp.MustCompile(`os\.Getenv\("([A-Z_][A-Z0-9_]*)"\)()`),
+	},
+	".js": {
+		regexp.MustCompile(`process\.env\.([A-Z_][A-Z0-9_]*)\b(\s*(?:\|\||\?\?))?`),
+		regexp.MustCompile(`process\.env\[["']([A-Z_][A-Z0-9_]*)["']\](\s*(?:\|\||\?\?))?`),
+	},
+	".py": {
+		regexp.MustCompile(`os\.environ\[\s*["']([A-Z_][A-Z0-9_]*)["']\s*\]()`),
+		regexp.MustCompile(`os\.(?:getenv|environ\.get)\(\s*["']([A-Z_][A-Z0-9_]*)["']\s*(,)?`),
+	},
+}
+
+// pythonSettingsReference matches the readers of django-environ, environs
+// and python-decouple, which are only looked for in settings modules.
+var pythonSettingsReference = regexp.MustCompile(`\b(?:env|config)(?:\.[a-z_]+)?\(\s*["']([A-Z_][A-Z0-9_]*)["']\s*(,\s*default\s*=)?`)
+
+func init() {
+	for _, ext := range []string{".mjs", ".cjs", ".jsx", ".ts", ".mts", ".cts", ".tsx"} {
+		envReferences[ext] = envReferences[".js"]
+	}
+}
+
+// envIgnored lists variables that runners or the runtime set on their own.
+var envIgnored = map[string]bool{
+	"CI": true, "DEBUG": true, "HOME": true, "HOST": true, "HOSTNAME": true,
+	"LANG": true, "NODE_ENV": true, "PATH": true, "PORT": true, "PWD": true,
+	"SHELL": true, "TERM": true, "TMPDIR": true, "TZ": true, "USER": true,
+}
+
+var envIgnoredPrefixes = []string{"ACTIONS_", "CI_", "FORGEJO_", "GITEA_", "GITHUB_", "GITLAB_", "RUNNER_"}
+
+// envSkippedDirs are not scanned for variable references.
+var envSkippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "venv": true, "dist": true,
+	"build": true, "__pycache__": true, "site-packages": true,
+}
+
+// detectEnvVars returns the variables the project needs at runtime, from the
+// first .env example file and from reads without a default in the sources.
+// Files and directories that cannot be read are skipped with a warning.
+func detectEnvVars(path string) ([]registry.EnvVar, []string) {
+	found := make(map[string]registry.EnvVar)
+	add := func(variable registry.EnvVar) {
+		if _, ok := found[variable.Name]; ok || envIgnored[variable.Name] {
+			return
+		}
+		for _, prefix := range envIgnoredPrefixes {
+			if strings.HasPrefix(variable.Name, prefix) {
+				return
+			}
+		}
+		found[variable.Name] = variable
+	}
+
+	var warnings []string
+	skip := func(err error) {
+		warnings = append(warnings, fmt.Sprintf("skipping environment variables: %v", err))
+	}
+	if name := registry.FirstExisting(path, envExampleFiles...); name != "" {
+		data, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			skip(fmt.Errorf("failed to read %s: %w", name, err))
+		}
+		for _, variable := range parseEnvExample(data) {
+			variable.Source = name
+			add(variable)
+		}
+	}
+
+	filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			skip(err)
+			if entry != nil && entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			if file != path && (strings.HasPrefix(entry.Name(), ".") || envSkippedDirs[entry.Name()]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		patterns := envReferences[filepath.Ext(file)]
+		if isPythonSettings(file) {
+			patterns = append(patterns[:len(patterns):len(patterns)], pythonSettingsReference)
+		}
+		if len(patterns) == 0 {
+			return nil
+		}
+		if info, err := entry.Info(); err != nil || info.Size() > 1<<20 {
+			return nil
+		}
+
+		rel, _ := filepath.Rel(path, file)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			skip(fmt.Errorf("failed to read %s: %w", filepath.ToSlash(rel), err))
+			return nil
+		}
+		for _, pattern := range patterns {
+			for _, match := range pattern.FindAllSubmatch(data, -1) {
+				if len(match[2]) == 0 {
+					add(registry.EnvVar{Name: string(match[1]), Source: filepath.ToSlash(rel)})
+				}
+			}
+		}
+		return nil
+	})
+
+	variables := make([]registry.EnvVar, 0, len(found))
+	for _, variable := range found {
+		variables = append(variables, variable)
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	return variables, warnings
+}
+
+// parseEnvExample reads KEY=value lines, using the comment right above a
+// variable as its description.
+func parseEnvExample(data []byte) []registry.EnvVar {
+	var variables []registry.EnvVar
+	var comment []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			comment = nil
+		case strings.HasPrefix(line, "#"):
+			comment = append(comment, strings.TrimSpace(strings.TrimLeft(line, "#")))
+		default:
+			name, _, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+			if ok {
+				variables = append(variables, registry.EnvVar{
+					Name:        strings.TrimSpace(name),
+					Description: strings.Join(comment, " "),
+				})
+			}
+			comment = nil
+		}
+	}
+	return variables
+}
+
+// isPythonSettings reports whether file is a settings module, such as
+// Django's settings.py or a settings package.
+func isPythonSettings(file string) bool {
+	if filepath.Ext(file) != ".py" {
+		return false
+	}
+	base := strings.TrimSuffix(filepath.Base(file), ".py")
+	return base == "settings" || base == "config" || filepath.Base(filepath.Dir(file)) == "settings"
+}
@@ -0,0 +1,102 @@
+package extractors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zraisan/AutoFlow/registry"
+)
+
+func TestDetectEnvVars(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".env.example": "# Signs sessions.\nSECRET_KEY=\nPORT=8080\n",
+		"main.go": `package main
+
+import "os"
+
+func main() {
+	_ = os.Getenv("DATABASE_URL")
+	_, _ = os.LookupEnv("LOG_LEVEL")
+	_ = os.Getenv("GITHUB_TOKEN")
+}
+`,
+		"web/app.ts":                 "const url = process.env.API_URL;\nconst mode = process.env.MODE ?? 'dev';\n",
+		"app/settings.py":            "import os\nREDIS = os.environ['REDIS_URL']\nDEBUG = os.getenv('DEBUG_SQL', '')\nSENTRY = env('SENTRY_DSN')\n",
+		"node_modules/dep/index.js":  "process.env.VENDORED\n",
+		".github/scripts/release.js": "process.env.RELEASE_TOKEN\n",
+		"app/helpers.py":             "env('NOT_SETTINGS')\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	variables, warnings := detectEnvVars(dir)
+	if len(warnings) > 0 {
+		t.Errorf("warnings = %q, want none", warnings)
+	}
+	want := []registry.EnvVar{
+		{Name: "API_URL", Source: "web/app.ts"},
+		{Name: "DATABASE_URL", Source: "main.go"},
+		{Name: "REDIS_URL", Source: "app/settings.py"},
+		{Name: "SECRET_KEY", Description: "Signs sessions.", Source: ".env.example"},
+		{Name: "SENTRY_DSN", Source: "app/settings.py"},
+	}
+	if len(variables) != len(want) {
+		t.Fatalf("detectEnvVars() = %+v, want %+v", variables, want)
+	}
+	for i := range want {
+		if variables[i] != want[i] {
+			t.Errorf("variable %d = %+v, want %+v", i, variables[i], want[i])
+		}
+	}
+}
+
+func TestDetectEnvVarsUnreadable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package main
+
+import "os"
+
+var token = os.Getenv("API_TOKEN")
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A dangling link is listed like a source file but cannot be read.
+	if err := os.Symlink(filepath.Join(dir, "missing.go"), filepath.Join(dir, "broken.go")); err != nil {
+		t.Skipf("symlinks are not supported: %v", err)
+	}
+	private := filepath.Join(dir, "private")
+	if err := os.Mkdir(private, 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(private, 0755) })
+
+	variables, warnings := detectEnvVars(dir)
+	if len(variables) != 1 || variables[0].Name != "API_TOKEN" {
+		t.Errorf("detectEnvVars() = %+v, want API_TOKEN from the readable files", variables)
+	}
+	if len(warnings) == 0 || !strings.Contains(warnings[0], "broken.go") {
+		t.Errorf("warnings = %q, want one naming broken.go", warnings)
+	}
+
+	// Extract reports the skipped files instead of failing.
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err := (&GolangExtractor{}).Extract(dir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(result.Env) != 1 || len(result.Warnings) == 0 {
+		t.Errorf("Extract() Env = %+v, Warnings = %q, want API_TOKEN and a warning", result.Env, result.Warnings)
+	}
+}
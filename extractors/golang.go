@@ -13,6 +13,13 @@ func init() {
 	registry.RegisterExtractor(&GolangExtractor{})
 }
 
+// The report tools run with exact versions, so every pipeline run builds the
+// same code.
+const (
+	gotestsum        = "gotest.tools/gotestsum@v1.13.0"
+	gocoverCobertura = "github.com/boumenot/gocover-cobertura@v1.5.0"
+)
+
 type GolangExtractor struct{}
 
 func (g *GolangExtractor) Name() string {
@@ -27,16 +34,40 @@ func (g *GolangExtractor) Extract(path string) (*registry.ExtractorResult, error
 
 	version, image := detectGoVersion(string(data))
 	result := &registry.ExtractorResult{
-		Runtime:        "go",
-		RuntimeVersion: version,
-		Image:          image,
-		PackageManager: "go",
+		Runtime:         "go",
+		RuntimeVersion:  version,
+		RuntimeVersions: detectGoVersions(string(data)),
+		Image:           image,
+		PackageManager:  "go",
 		Scripts: map[string]string{
 			"Build": "go build -v ./...",
 			"Test":  "go test -v ./...",
 		},
+		Lockfile: registry.FirstExisting(path, "go.sum"),
+		TestReport: &registry.TestReport{
+			Script:   "go run " + gotestsum + " --junitfile report.xml -- -coverprofile=coverage.out ./... && go run " + gocoverCobertura + " < coverage.out > coverage.xml",
+			JUnit:    "report.xml",
+			Coverage: "coverage.xml",
+		},
+	}
+
+	// go build refuses -o with a directory when there is no main package,
+	// so only modules with commands collect their binaries in bin/.
+	if len(registry.GoBinaries(path)) > 0 {
+		result.Scripts["Build"] = "go build -v -o bin/ ./..."
+		result.Artifacts = []string{"bin/"}
 	}
 
+	services, err := detectComposeServices(path)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("skipping services: %v", err))
+	}
+	result.Services = services
+
+	env, envWarnings := detectEnvVars(path)
+	result.Env = env
+	result.Warnings = append(result.Warnings, envWarnings...)
+
 	return result, nil
 }
 
@@ -50,3 +81,22 @@ func detectGoVersion(gomod string) (string, string) {
 	}
 	return "latest", "golang:latest"
 }
+
+// detectGoVersions returns the minimum version from the go directive and the
+// toolchain version when the module pins a different one.
+func detectGoVersions(gomod string) []string {
+	var versions []string
+	for line := range strings.SplitSeq(gomod, "\n") {
+		line = strings.TrimSpace(line)
+		if after, found := strings.CutPrefix(line, "go "); found {
+			versions = append(versions, strings.TrimSpace(after))
+		}
+		if after, found := strings.CutPrefix(line, "toolchain go"); found {
+			versions = append(versions, strings.TrimSpace(after))
+		}
+	}
+	if len(versions) < 2 || versions[0] == versions[1] {
+		return nil
+	}
+	return versions
+}
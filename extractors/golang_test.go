@@ -0,0 +1,61 @@
+package extractors
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGolangExtractBuild(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		build     string
+		artifacts []string
+	}{
+		{
+			name:  "library",
+			files: map[string]string{"lib.go": "package lib\n"},
+			build: "go build -v ./...",
+		},
+		{
+			name:      "command at the root",
+			files:     map[string]string{"main.go": "package main\n\nfunc main() {}\n"},
+			build:     "go build -v -o bin/ ./...",
+			artifacts: []string{"bin/"},
+		},
+		{
+			name:      "commands under cmd",
+			files:     map[string]string{"lib.go": "package lib\n", "cmd/tool/main.go": "package main\n\nfunc main() {}\n"},
+			build:     "go build -v -o bin/ ./...",
+			artifacts: []string{"bin/"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			test.files["go.mod"] = "module example.com/app\n\ngo 1.22\n"
+			for name, content := range test.files {
+				file := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := (&GolangExtractor{}).Extract(dir)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if got := result.Scripts["Build"]; got != test.build {
+				t.Errorf("Build = %q, want %q", got, test.build)
+			}
+			if !slices.Equal(result.Artifacts, test.artifacts) {
+				t.Errorf("Artifacts = %q, want %q", result.Artifacts, test.artifacts)
+			}
+		})
+	}
+}
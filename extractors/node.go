@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/zraisan/AutoFlow/registry"
@@ -16,11 +17,48 @@ func init() {
 
 type NodeExtractor struct{}
 
+// nodeLockfiles lists the lockfiles of each package manager. Bun 1.2
+// replaced the binary bun.lockb with the text bun.lock.
+var nodeLockfiles = map[string][]string{
+	"npm":  {"package-lock.json"},
+	"pnpm": {"pnpm-lock.yaml"},
+	"yarn": {"yarn.lock"},
+	"bun":  {"bun.lock", "bun.lockb"},
+}
+
+// nodeLockfileOrder is the order lockfiles are checked in when the project
+// does not name its package manager, for repositories that carry several.
+var nodeLockfileOrder = []string{"pnpm", "yarn", "bun", "npm"}
+
+// nodeTestRunners are the test runners whose reports are not collected.
+var nodeTestRunners = []string{"mocha", "ava", "tap", "uvu", "playwright", "cypress", "node"}
+
+// nodeRunPrefixes are the commands that run a package.json script with each
+// package manager.
+var nodeRunPrefixes = map[string]string{
+	"npm":  "npm run ",
+	"pnpm": "pnpm run ",
+	"yarn": "yarn run ",
+	"bun":  "bun run ",
+}
+
 type packageJSON struct {
-	Scripts map[string]string `json:"scripts"`
-	Engines struct {
+	PackageManager  string            `json:"packageManager"`
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+	Engines         struct {
 		Node string `json:"node"`
 	} `json:"engines"`
+	Volta struct {
+		Node string `json:"node"`
+	} `json:"volta"`
+}
+
+func (p packageJSON) hasDependency(name string) bool {
+	_, dep := p.Dependencies[name]
+	_, devDep := p.DevDependencies[name]
+	return dep || devDep
 }
 
 func (n *NodeExtractor) Name() string {
@@ -39,66 +77,154 @@ func (n *NodeExtractor) Extract(path string) (*registry.ExtractorResult, error)
 	}
 
 	version, image := detectNodeVersion(path, pkg)
+	packageManager, packageManagerVersion := detectNodePackageManager(path, pkg)
+	lockfile := registry.FirstExisting(path, nodeLockfiles[packageManager]...)
+	scripts := normalizeNodeScripts(pkg.Scripts, nodeRunPrefixes[packageManager])
+	scripts["Install"] = nodeInstallScript(path, packageManager, packageManagerVersion, lockfile)
 	result := &registry.ExtractorResult{
-		Runtime:        "node",
-		RuntimeVersion: version,
-		Image:          image,
-		PackageManager: detectNodePackageManager(path),
-		Scripts:        normalizeNodeScripts(pkg.Scripts),
+		Runtime:               "node",
+		RuntimeVersion:        version,
+		RuntimeVersions:       detectNodeVersions(pkg.Engines.Node),
+		Image:                 image,
+		PackageManager:        packageManager,
+		PackageManagerVersion: packageManagerVersion,
+		Lockfile:              lockfile,
+		Scripts:               scripts,
 	}
+	if result.Scripts["Build"] != "" {
+		result.Artifacts = []string{"dist/"}
+	}
+	if test := result.Scripts["Test"]; test != "" {
+		command := pkg.Scripts[strings.TrimPrefix(test, nodeRunPrefixes[packageManager])]
+		result.TestReport = detectNodeTestReport(pkg, test, command, packageManager)
+	}
+
+	services, err := detectComposeServices(path)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("skipping services: %v", err))
+	}
+	result.Services = services
+
+	env, envWarnings := detectEnvVars(path)
+	result.Env = env
+	result.Warnings = append(result.Warnings, envWarnings...)
 
 	return result, nil
 }
 
-func detectNodeVersion(path string, pkg packageJSON) (string, string) {
-	version := "20"
+// detectNodeTestReport passes the reporter flags of the project's test runner
+// through the test script. Jest needs jest-junit for JUnit output. Only npm
+// needs "--" to pass flags through; the other managers hand it to the runner.
+func detectNodeTestReport(pkg packageJSON, test, command, packageManager string) *registry.TestReport {
+	separator := " "
+	if packageManager == "npm" {
+		separator = " -- "
+	}
 
-	if nvmrc, err := os.ReadFile(filepath.Join(path, ".nvmrc")); err == nil {
-		v := strings.TrimSpace(string(nvmrc))
-		v = strings.TrimPrefix(v, "v")
-		if v != "" {
-			version = v
+	switch nodeTestRunner(pkg, command) {
+	case "vitest":
+		report := &registry.TestReport{
+			Script: test + separator + "--reporter=default --reporter=junit --outputFile.junit=report.xml",
+			JUnit:  "report.xml",
+		}
+		if pkg.hasDependency("@vitest/coverage-v8") || pkg.hasDependency("@vitest/coverage-istanbul") {
+			report.Script += " --coverage.enabled --coverage.reporter=cobertura"
+			report.Coverage = "coverage/cobertura-coverage.xml"
 		}
-	} else if pkg.Engines.Node != "" {
-		v := pkg.Engines.Node
-		v = strings.TrimLeft(v, ">=^~")
-		v = strings.Split(v, " ")[0]
-		if v != "" {
-			version = v
+		return report
+	case "jest":
+		if !pkg.hasDependency("jest-junit") {
+			return nil
+		}
+		return &registry.TestReport{
+			Script:   "JEST_JUNIT_OUTPUT_FILE=report.xml " + test + separator + "--ci --reporters=default --reporters=jest-junit --coverage --coverageReporters=cobertura",
+			JUnit:    "report.xml",
+			Coverage: "coverage/cobertura-coverage.xml",
+		}
+	}
+	return nil
+}
+
+// nodeTestRunner returns the runner the test script invokes. Scripts that
+// name no known runner, such as those delegating to another script, fall back
+// to the runner the project depends on.
+func nodeTestRunner(pkg packageJSON, command string) string {
+	words := strings.Fields(command)
+	for _, runner := range []string{"vitest", "jest"} {
+		if slices.Contains(words, runner) {
+			return runner
+		}
+	}
+	for _, word := range words {
+		if slices.Contains(nodeTestRunners, word) {
+			return word
 		}
 	}
 
-	return version, "node:" + version + "-alpine"
+	switch {
+	case pkg.hasDependency("vitest"):
+		return "vitest"
+	case pkg.hasDependency("jest"):
+		return "jest"
+	}
+	return ""
 }
 
-func detectNodePackageManager(path string) string {
-	if _, err := os.Stat(filepath.Join(path, "pnpm-lock.yaml")); err == nil {
-		return "pnpm"
+// detectNodePackageManager returns the package manager and its pinned
+// version. The packageManager field of package.json, which Corepack enforces,
+// takes precedence over the lockfiles found in the project.
+func detectNodePackageManager(path string, pkg packageJSON) (string, string) {
+	if name, version, ok := parsePackageManagerField(pkg.PackageManager); ok {
+		return name, version
 	}
-	if _, err := os.Stat(filepath.Join(path, "yarn.lock")); err == nil {
-		return "yarn"
+	for _, name := range nodeLockfileOrder {
+		if registry.FirstExisting(path, nodeLockfiles[name]...) != "" {
+			return name, ""
+		}
 	}
-	if _, err := os.Stat(filepath.Join(path, "bun.lockb")); err == nil {
-		return "bun"
+	return "npm", ""
+}
+
+// parsePackageManagerField splits a packageManager value such as
+// "pnpm@9.12.0+sha512.abc" into the manager and its version, dropping the
+// integrity hash.
+func parsePackageManagerField(field string) (string, string, bool) {
+	name, version, found := strings.Cut(strings.TrimSpace(field), "@")
+	if _, known := nodeLockfiles[name]; !found || !known {
+		return "", "", false
 	}
-	return "npm"
+	version, _, _ = strings.Cut(version, "+")
+	return name, version, version != ""
 }
 
-func normalizeNodeScripts(raw map[string]string) map[string]string {
-	s := make(map[string]string)
-	s["Install"] = "npm install"
-	for key := range raw {
-		keyLower := strings.ToLower(key)
-		switch {
-		case strings.Contains(keyLower, "lint"):
-			s["Lint"] = "npm run " + key
-		case strings.Contains(keyLower, "test"):
-			s["Test"] = "npm run " + key
-		case strings.Contains(keyLower, "build"):
-			s["Build"] = "npm run " + key
-		case strings.Contains(keyLower, "deploy"):
-			s["Deploy"] = "npm run " + key
+// nodeInstallScript installs exactly what the lockfile records, failing when
+// it is out of date with package.json.
+func nodeInstallScript(path, packageManager, packageManagerVersion, lockfile string) string {
+	if lockfile == "" {
+		return packageManager + " install"
+	}
+	switch packageManager {
+	case "npm":
+		return "npm ci"
+	case "yarn":
+		if isYarnBerry(path, packageManagerVersion) {
+			return "yarn install --immutable"
 		}
+		return "yarn install --frozen-lockfile"
+	}
+	return packageManager + " install --frozen-lockfile"
+}
+
+// isYarnBerry reports whether the project uses Yarn 2 or later, going by the
+// pinned version, or else by .yarnrc.yml and the __metadata of its YAML
+// lockfile.
+func isYarnBerry(path, version string) bool {
+	if version != "" {
+		return !strings.HasPrefix(version, "1.")
+	}
+	if registry.FirstExisting(path, ".yarnrc.yml") != "" {
+		return true
 	}
-	return s
+	data, err := os.ReadFile(filepath.Join(path, "yarn.lock"))
+	return err == nil && strings.Contains(string(data), "__metadata:")
 }
@@ -0,0 +1,229 @@
+package extractors
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// nodeScriptCategory maps package.json scripts to a script key. A script
+// whose name is one of names is taken over one that has one of the words in
+// its name, such as "lint" over "lint:js" over "ci:lint". Refining
+// categories narrow down another one, so "test:e2e" is an end-to-end test
+// rather than a unit test.
+type nodeScriptCategory struct {
+	key      string
+	names    []string
+	words    []string
+	refining bool
+}
+
+var nodeScriptCategories = []nodeScriptCategory{
+	{key: "TestE2E", names: []string{"test:e2e", "e2e"}, words: []string{"e2e", "playwright", "cypress"}, refining: true},
+	{key: "TestIntegration", names: []string{"test:integration", "integration"}, words: []string{"integration"}, refining: true},
+	{key: "FormatCheck", names: []string{"format:check", "check:format", "prettier:check", "fmt:check"}},
+	{key: "Typecheck", names: []string{"typecheck", "type-check", "check-types", "types", "tsc"}, words: []string{"typecheck", "types", "tsc"}},
+	{key: "Lint", names: []string{"lint"}, words: []string{"lint", "eslint"}},
+	{key: "Test", names: []string{"test", "test:unit", "unit"}, words: []string{"test", "unit", "spec"}},
+	{key: "Build", names: []string{"build"}, words: []string{"build", "compile"}},
+	{key: "Deploy", names: []string{"deploy"}, words: []string{"deploy"}},
+}
+
+// nodeScriptVariants mark the scripts meant for local use: watchers, dev
+// servers and scripts that rewrite files.
+var nodeScriptVariants = []string{"watch", "fix", "dev", "serve", "debug", "ui", "open", "update", "write"}
+
+// nodeLifecycleScripts are run by the package manager itself around
+// installing, packing and publishing.
+var nodeLifecycleScripts = []string{
+	"preinstall", "install", "postinstall", "prepare", "prepublish", "prepublishOnly",
+	"prepack", "postpack", "publish", "postpublish", "preversion", "version",
+	"postversion", "dependencies",
+}
+
+// nodeLocalFlags are the flags of commands that watch or rewrite files.
+var nodeLocalFlags = regexp.MustCompile(`(^|\s)--(watch|fix|write)\b`)
+
+// nodeCommandSeparators split a script into the commands it chains.
+var nodeCommandSeparators = regexp.MustCompile(`&&|\|\||[;|&]`)
+
+// normalizeNodeScripts picks the best script for each category, ranking
+// exact names over names starting with a category word over names that
+// contain one, and alphabetically after that. Lifecycle hooks and local
+// variants are never picked, nor are tests another picked test already runs.
+func normalizeNodeScripts(raw map[string]string, run string) map[string]string {
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	picked := make(map[string]string)
+	ranks := make(map[string]int)
+	for _, name := range names {
+		if isNodeLifecycleHook(name, raw) || isNodeLocalScript(name, raw[name]) {
+			continue
+		}
+		key, rank, ok := classifyNodeScript(name, raw[name])
+		if !ok {
+			continue
+		}
+		if current, found := ranks[key]; !found || rank < current {
+			picked[key], ranks[key] = name, rank
+		}
+	}
+
+	s := make(map[string]string)
+	for key, name := range picked {
+		if !strings.HasPrefix(key, "Test") || !nodeTestRunByOther(name, picked, raw) {
+			s[key] = run + name
+		}
+	}
+	return s
+}
+
+// classifyNodeScript returns the script key of a script and its rank, lower
+// being better. Otherwise the first word that names a category decides, so
+// "build:types" builds type declarations rather than checking them.
+func classifyNodeScript(name, command string) (string, int, bool) {
+	lower := strings.ToLower(name)
+	words := nodeScriptWords(lower)
+
+	for _, category := range nodeScriptCategories {
+		if slices.Contains(category.names, lower) {
+			return category.key, 0, true
+		}
+	}
+	for _, category := range nodeScriptCategories {
+		if !category.refining {
+			continue
+		}
+		if i := slices.IndexFunc(words, func(word string) bool { return slices.Contains(category.words, word) }); i >= 0 {
+			return category.key, min(i, 1) + 1, true
+		}
+	}
+	for i, word := range words {
+		for _, category := range nodeScriptCategories {
+			if slices.Contains(category.words, word) {
+				return category.key, min(i, 1) + 1, true
+			}
+		}
+	}
+
+	// A "format" script may check rather than write, e.g. "prettier --check .".
+	if slices.ContainsFunc(words, func(word string) bool { return word == "format" || word == "prettier" || word == "fmt" }) {
+		if slices.Contains(words, "check") || strings.Contains(command, "--check") || strings.Contains(command, "--list-different") {
+			return "FormatCheck", 1, true
+		}
+	}
+	return "", 0, false
+}
+
+// nodeScriptWords splits a script name such as "test:e2e:ci" into its words.
+func nodeScriptWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r == ':' || r == '-' || r == '_' || r == '.' || r == '/'
+	})
+}
+
+// isNodeLifecycleHook reports whether the package manager runs the script
+// itself, either as a lifecycle script or as the pre or post hook of another
+// script such as "pretest".
+func isNodeLifecycleHook(name string, raw map[string]string) bool {
+	if slices.Contains(nodeLifecycleScripts, name) {
+		return true
+	}
+	for _, prefix := range []string{"pre", "post"} {
+		if base, found := strings.CutPrefix(name, prefix); found {
+			if _, ok := raw[base]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isNodeLocalScript reports whether a script watches, serves or rewrites
+// files, going by its name such as "lint:fix" or its flags.
+func isNodeLocalScript(name, command string) bool {
+	for _, word := range nodeScriptWords(strings.ToLower(name)) {
+		if slices.Contains(nodeScriptVariants, word) {
+			return true
+		}
+	}
+	return nodeLocalFlags.MatchString(command)
+}
+
+// nodeTestRunByOther reports whether another picked test script already
+// runs name, as "test" does when it is "vitest run && npm run test:e2e" or
+// "run-s test:unit test:e2e".
+func nodeTestRunByOther(name string, picked map[string]string, raw map[string]string) bool {
+	for key, other := range picked {
+		if !strings.HasPrefix(key, "Test") || other == name {
+			continue
+		}
+		for _, script := range nodeScriptsRunBy(raw[other]) {
+			// npm-run-all takes globs such as "test:*", matching across
+			// ":" like path.Match does across "/".
+			pattern := strings.ReplaceAll(script, ":", "/")
+			if matched, _ := path.Match(pattern, strings.ReplaceAll(name, ":", "/")); matched || script == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeScriptsRunBy returns the names of the package.json scripts a command
+// runs through the package manager, such as "npm run lint", "pnpm test:e2e"
+// or "run-s build test:*". Arguments to other tools, like the "integration"
+// of "vitest run integration", are not script names.
+func nodeScriptsRunBy(command string) []string {
+	var scripts []string
+	for _, segment := range nodeCommandSeparators.Split(command, -1) {
+		fields := strings.Fields(segment)
+		// Skip variable assignments and cross-env in front of the command.
+		for len(fields) > 0 && (strings.Contains(fields[0], "=") || fields[0] == "cross-env") {
+			fields = fields[1:]
+		}
+		if len(fields) < 2 {
+			continue
+		}
+
+		args := nodeNonFlags(fields[1:])
+		switch fields[0] {
+		case "run-s", "run-p", "npm-run-all":
+			scripts = append(scripts, args...)
+			continue
+		case "npm", "bun":
+			if len(args) > 1 && (args[0] == "run" || args[0] == "run-script") {
+				scripts = append(scripts, args[1])
+			} else if len(args) > 0 && fields[0] == "npm" && (args[0] == "test" || args[0] == "t") {
+				scripts = append(scripts, "test")
+			}
+		case "pnpm", "yarn":
+			// Both run a script when it is named without "run".
+			if len(args) > 1 && args[0] == "run" {
+				scripts = append(scripts, args[1])
+			} else if len(args) > 0 {
+				scripts = append(scripts, args[0])
+			}
+		}
+	}
+	return scripts
+}
+
+// nodeNonFlags returns the arguments before "--" that are not flags.
+func nodeNonFlags(fields []string) []string {
+	var args []string
+	for _, field := range fields {
+		if field == "--" {
+			break
+		}
+		if !strings.HasPrefix(field, "-") {
+			args = append(args, strings.Trim(field, `"'`))
+		}
+	}
+	return args
+}
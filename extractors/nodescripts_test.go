@@ -0,0 +1,126 @@
+package extractors
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestClassifyNodeScript(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		key     string
+		rank    int
+		ok      bool
+	}{
+		{"test", "vitest run", "Test", 0, true},
+		{"test:unit", "vitest run", "Test", 0, true},
+		{"test:ci", "vitest run", "Test", 1, true},
+		{"ci:test", "vitest run", "Test", 2, true},
+		{"test:e2e", "playwright test", "TestE2E", 0, true},
+		{"e2e:ci", "playwright test", "TestE2E", 1, true},
+		{"test:integration:ci", "vitest run integration", "TestIntegration", 2, true},
+		{"cypress:run", "cypress run", "TestE2E", 1, true},
+		{"lint", "eslint .", "Lint", 0, true},
+		{"lint:js", "eslint .", "Lint", 1, true},
+		{"ci:lint", "eslint .", "Lint", 2, true},
+		{"build", "tsc -b", "Build", 0, true},
+		{"build:types", "tsc --emitDeclarationOnly", "Build", 1, true},
+		{"typecheck", "tsc --noEmit", "Typecheck", 0, true},
+		{"format:check", "prettier --check .", "FormatCheck", 0, true},
+		{"format", "prettier --check .", "FormatCheck", 1, true},
+		{"format", "prettier --write .", "", 0, false},
+		{"start", "node server.js", "", 0, false},
+	}
+	for _, test := range tests {
+		key, rank, ok := classifyNodeScript(test.name, test.command)
+		if key != test.key || rank != test.rank || ok != test.ok {
+			t.Errorf("classifyNodeScript(%q, %q) = %q, %d, %v, want %q, %d, %v",
+				test.name, test.command, key, rank, ok, test.key, test.rank, test.ok)
+		}
+	}
+}
+
+func TestNormalizeNodeScripts(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "end-to-end test next to unit test",
+			raw:  map[string]string{"test": "vitest run", "test:e2e": "playwright test"},
+			want: map[string]string{"Test": "npm run test", "TestE2E": "npm run test:e2e"},
+		},
+		{
+			name: "pre and post hooks",
+			raw:  map[string]string{"test": "jest", "pretest": "npm run lint", "build": "tsc", "prebuild": "rimraf dist", "postbuild": "cp -r assets dist"},
+			want: map[string]string{"Test": "npm run test", "Build": "npm run build"},
+		},
+		{
+			name: "local variants",
+			raw:  map[string]string{"lint": "eslint .", "lint:fix": "eslint . --fix", "build": "vite build", "build:watch": "vite build --watch", "test:dev": "vitest"},
+			want: map[string]string{"Lint": "npm run lint", "Build": "npm run build"},
+		},
+		{
+			name: "local flags",
+			raw:  map[string]string{"check": "eslint --fix .", "test": "jest --watch", "test:ci": "jest --ci"},
+			want: map[string]string{"Test": "npm run test:ci"},
+		},
+		{
+			name: "lifecycle scripts",
+			raw:  map[string]string{"prepare": "husky", "postinstall": "npm run build", "build": "tsc"},
+			want: map[string]string{"Build": "npm run build"},
+		},
+		{
+			name: "exact name over prefix over word",
+			raw:  map[string]string{"ci:lint": "eslint .", "lint:js": "eslint .", "lint": "eslint ."},
+			want: map[string]string{"Lint": "npm run lint"},
+		},
+		{
+			name: "test running the end-to-end tests",
+			raw:  map[string]string{"test": "vitest run && npm run test:e2e", "test:e2e": "playwright test"},
+			want: map[string]string{"Test": "npm run test"},
+		},
+		{
+			name: "test running the others through run-s",
+			raw:  map[string]string{"test": "run-s test:*", "test:integration": "vitest run integration", "test:e2e": "playwright test"},
+			want: map[string]string{"Test": "npm run test"},
+		},
+		{
+			name: "tool argument named like a script",
+			raw:  map[string]string{"test": "vitest run integration", "integration": "jest -c integration.config.js"},
+			want: map[string]string{"Test": "npm run test", "TestIntegration": "npm run integration"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := normalizeNodeScripts(test.raw, "npm run "); !maps.Equal(got, test.want) {
+				t.Errorf("normalizeNodeScripts() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNodeScriptsRunBy(t *testing.T) {
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"npm run lint && npm test", []string{"lint", "test"}},
+		{"npm run --silent test:e2e -- --headed", []string{"test:e2e"}},
+		{"pnpm test:unit; pnpm run test:e2e", []string{"test:unit", "test:e2e"}},
+		{"yarn build || yarn run build:fallback", []string{"build", "build:fallback"}},
+		{"bun run test:e2e", []string{"test:e2e"}},
+		{"run-p -c lint test:*", []string{"lint", "test:*"}},
+		{"cross-env CI=1 npm-run-all --serial build test", []string{"build", "test"}},
+		{"vitest run integration", nil},
+		{"jest --selectProjects e2e", nil},
+	}
+	for _, test := range tests {
+		if got := nodeScriptsRunBy(test.command); !slices.Equal(got, test.want) {
+			t.Errorf("nodeScriptsRunBy(%q) = %q, want %q", test.command, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package extractors
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// nodeLTSVersions are the Node.js LTS release lines that version ranges
+// resolve against, oldest first.
+var nodeLTSVersions = []int{18, 20, 22, 24}
+
+// nodeLTSCodenames maps the codenames nvm accepts as "lts/<name>" to their
+// release lines.
+var nodeLTSCodenames = map[string]int{
+	"argon":    4,
+	"boron":    6,
+	"carbon":   8,
+	"dubnium":  10,
+	"erbium":   12,
+	"fermium":  14,
+	"gallium":  16,
+	"hydrogen": 18,
+	"iron":     20,
+	"jod":      22,
+	"krypton":  24,
+}
+
+// detectNodeVersion returns the version to run, taken from .nvmrc,
+// .node-version, .tool-versions, the Volta pin or the engines range, in that
+// order, and otherwise the newest LTS release.
+func detectNodeVersion(path string, pkg packageJSON) (string, string) {
+	specs := []string{
+		readVersionFile(path, ".nvmrc"),
+		readVersionFile(path, ".node-version"),
+		toolVersionsEntry(path, "nodejs", "node"),
+		pkg.Volta.Node,
+		pkg.Engines.Node,
+	}
+
+	version := strconv.Itoa(nodeLTSVersions[len(nodeLTSVersions)-1])
+	for _, spec := range specs {
+		if resolved, ok := resolveNodeVersion(spec); ok {
+			version = resolved
+			break
+		}
+	}
+	return version, "node:" + version + "-alpine"
+}
+
+// detectNodeVersions resolves the engines range to the LTS majors it allows,
+// returning nil when fewer than two match.
+func detectNodeVersions(engines string) []string {
+	if engines == "" {
+		return nil
+	}
+	r, ok := parseSemverRange(engines)
+	if !ok {
+		return nil
+	}
+
+	var versions []string
+	for _, major := range nodeLTSVersions {
+		if r.allowsMajor(major) {
+			versions = append(versions, strconv.Itoa(major))
+		}
+	}
+	if len(versions) < 2 {
+		return nil
+	}
+	return versions
+}
+
+// resolveNodeVersion turns a version spec into an image tag. Exact versions
+// are kept, nvm aliases such as "lts/iron" name their release line, and
+// ranges resolve to the newest LTS release they allow, or to the nearest
+// major they allow when they exclude every current LTS release.
+func resolveNodeVersion(spec string) (string, bool) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return "", false
+	}
+	newest := nodeLTSVersions[len(nodeLTSVersions)-1]
+
+	// The current release line is not in the table, so the newest LTS
+	// release stands in for it.
+	switch spec {
+	case "node", "stable", "latest", "current", "lts", "lts/*", "lts/latest":
+		return strconv.Itoa(newest), true
+	}
+	if name, found := strings.CutPrefix(spec, "lts/"); found {
+		// "lts/-1" is the LTS release before the newest.
+		if back, err := strconv.Atoi(name); err == nil && back < 0 {
+			if i := len(nodeLTSVersions) - 1 + back; i >= 0 {
+				return strconv.Itoa(nodeLTSVersions[i]), true
+			}
+			return "", false
+		}
+		if major, ok := nodeLTSCodenames[name]; ok {
+			return strconv.Itoa(major), true
+		}
+		return "", false
+	}
+
+	version := strings.TrimPrefix(spec, "v")
+	if _, parts, ok := parseSemverPartial(version); ok && parts == 3 {
+		return version, true
+	}
+
+	r, ok := parseSemverRange(spec)
+	if !ok {
+		return "", false
+	}
+	for i := len(nodeLTSVersions) - 1; i >= 0; i-- {
+		if r.allowsMajor(nodeLTSVersions[i]) {
+			return strconv.Itoa(nodeLTSVersions[i]), true
+		}
+	}
+
+	// A range below the current LTS releases, such as "<16", resolves to the
+	// newest older LTS release it allows, or else to its highest major.
+	older := slices.Sorted(maps.Values(nodeLTSCodenames))
+	for i := len(older) - 1; i >= 0; i-- {
+		if r.allowsMajor(older[i]) {
+			return strconv.Itoa(older[i]), true
+		}
+	}
+	if major, ok := r.highestMajor(); ok && major > 0 {
+		return strconv.Itoa(major), true
+	}
+	// A range above them, such as ">=26", resolves to its lowest major.
+	if major, ok := r.lowestMajor(); ok && major > 0 {
+		return strconv.Itoa(major), true
+	}
+	return "", false
+}
+
+// readVersionFile returns the first line of a version file such as .nvmrc,
+// skipping blank lines and comments.
+func readVersionFile(path, name string) string {
+	data, err := os.ReadFile(filepath.Join(path, name))
+	if err != nil {
+		return ""
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// toolVersionsEntry returns the first version .tool-versions lists for one
+// of the tool names used by asdf and mise.
+func toolVersionsEntry(path string, tools ...string) string {
+	data, err := os.ReadFile(filepath.Join(path, ".tool-versions"))
+	if err != nil {
+		return ""
+	}
+	for line := range strings.SplitSeq(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		fields := strings.Fields(line)
+		if len(fields) > 1 && slices.Contains(tools, fields[0]) {
+			return fields[1]
+		}
+	}
+	return ""
+}
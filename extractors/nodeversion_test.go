@@ -0,0 +1,31 @@
+package extractors
+
+import "testing"
+
+func TestResolveNodeVersion(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+		ok   bool
+	}{
+		{">=18", "24", true},
+		{"^20.11", "20", true},
+		{"20.11.1", "20.11.1", true},
+		{"lts/iron", "20", true},
+		{"lts/-1", "22", true},
+		{"<16", "14", true},
+		{"<=14", "14", true},
+		{">=12 <16", "14", true},
+		{"^15.2", "15", true},
+		{"<17.0.0", "16", true},
+		{">=26", "26", true},
+		{"^0.10", "", false},
+		{"", "", false},
+	}
+	for _, test := range tests {
+		got, ok := resolveNodeVersion(test.spec)
+		if got != test.want || ok != test.ok {
+			t.Errorf("resolveNodeVersion(%q) = %q, %v, want %q, %v", test.spec, got, ok, test.want, test.ok)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package extractors
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// pyproject is a parsed pyproject.toml. A project without one has an empty
+// document, so lookups find nothing and need no special casing.
+type pyproject struct {
+	tomlDocument
+}
+
+// requirementName matches the distribution name at the start of a PEP 508
+// requirement such as "Django[argon2]>=4.2; python_version > '3.9'".
+var requirementName = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// pythonRequirementFiles are the pip requirement files whose packages count
+// as project dependencies.
+var pythonRequirementFiles = []string{
+	"requirements.txt", "requirements-dev.txt", "requirements-test.txt",
+	"requirements/dev.txt", "requirements/test.txt", "dev-requirements.txt",
+}
+
+func readPyproject(path string) (pyproject, error) {
+	data, err := os.ReadFile(filepath.Join(path, "pyproject.toml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return pyproject{}, nil
+	}
+	if err != nil {
+		return pyproject{}, fmt.Errorf("failed to read pyproject.toml: %w", err)
+	}
+
+	document, err := parseTOML(string(data))
+	if err != nil {
+		return pyproject{}, fmt.Errorf("failed to parse pyproject.toml: %w", err)
+	}
+	return pyproject{document}, nil
+}
+
+// buildBackend returns the build-backend of [build-system], such as
+// "poetry.core.masonry.api" or "hatchling.build".
+func (p pyproject) buildBackend() string {
+	return p.str("build-system", "build-backend")
+}
+
+// dependencies returns the normalized names of every package the project
+// declares: runtime dependencies, optional dependencies, dependency groups
+// and the development dependencies of Poetry, uv, PDM and Hatch.
+func (p pyproject) dependencies() map[string]bool {
+	deps := make(map[string]bool)
+	addRequirements := func(value any) {
+		list, _ := value.([]any)
+		for _, item := range list {
+			// Dependency groups may include other groups as tables.
+			if requirement, ok := item.(string); ok {
+				if name := pythonPackageName(requirement); name != "" {
+					deps[name] = true
+				}
+			}
+		}
+	}
+	addGroups := func(groups map[string]any) {
+		for _, group := range groups {
+			addRequirements(group)
+		}
+	}
+	addNames := func(table map[string]any) {
+		for name := range table {
+			if name != "python" {
+				deps[pythonPackageName(name)] = true
+			}
+		}
+	}
+
+	addRequirements(p.lookup("project", "dependencies"))
+	addGroups(p.table("project", "optional-dependencies"))
+	addGroups(p.table("dependency-groups"))
+
+	addNames(p.table("tool", "poetry", "dependencies"))
+	addNames(p.table("tool", "poetry", "dev-dependencies"))
+	for name := range p.table("tool", "poetry", "group") {
+		addNames(p.table("tool", "poetry", "group", name, "dependencies"))
+	}
+
+	addRequirements(p.lookup("tool", "uv", "dev-dependencies"))
+	addGroups(p.table("tool", "pdm", "dev-dependencies"))
+	for name := range p.table("tool", "hatch", "envs") {
+		addRequirements(p.lookup("tool", "hatch", "envs", name, "dependencies"))
+		addRequirements(p.lookup("tool", "hatch", "envs", name, "extra-dependencies"))
+	}
+	return deps
+}
+
+// pythonPackageName returns the normalized distribution name of a
+// requirement, so that "Pytest_Cov" and "pytest-cov" compare equal.
+func pythonPackageName(requirement string) string {
+	match := requirementName.FindStringSubmatch(requirement)
+	if match == nil {
+		return ""
+	}
+	name := strings.ToLower(match[1])
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+// pythonDependencies combines the packages of pyproject.toml with those of
+// the pip requirement files, the Pipfile and the conda environment.
+func pythonDependencies(path string, project pyproject, conda *condaEnvironment) map[string]bool {
+	deps := project.dependencies()
+	for _, name := range conda.packages() {
+		deps[name] = true
+	}
+	if data, err := os.ReadFile(filepath.Join(path, "Pipfile")); err == nil {
+		if pipfile, err := parseTOML(string(data)); err == nil {
+			for _, section := range []string{"packages", "dev-packages"} {
+				for name := range pipfile.table(section) {
+					deps[pythonPackageName(name)] = true
+				}
+			}
+		}
+	}
+	for _, name := range pythonRequirementFiles {
+		file, err := os.Open(filepath.Join(path, name))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			// Options such as -r, -e and --index-url name no package.
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+				continue
+			}
+			if name := pythonPackageName(line); name != "" {
+				deps[name] = true
+			}
+		}
+		file.Close()
+	}
+	return deps
+}
@@ -1,9 +1,13 @@
 package extractors
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/zraisan/AutoFlow/registry"
@@ -15,106 +19,275 @@ func init() {
 
 type PythonExtractor struct{}
 
+var pythonLockfiles = map[string]string{
+	"pip":    "requirements.txt",
+	"uv":     "uv.lock",
+	"poetry": "poetry.lock",
+	"pdm":    "pdm.lock",
+	"pipenv": "Pipfile.lock",
+}
+
+// pythonRunPrefixes runs a tool inside the package manager's environment.
+// Conda environments are run by name instead.
+var pythonRunPrefixes = map[string]string{
+	"pip":    "",
+	"uv":     "uv run ",
+	"poetry": "poetry run ",
+	"pdm":    "pdm run ",
+	"hatch":  "hatch run ",
+	"pipenv": "pipenv run ",
+}
+
+// condaImage comes with conda and mamba preinstalled.
+const condaImage = "condaforge/miniforge3:latest"
+
 func (p *PythonExtractor) Name() string {
 	return "Python"
 }
 
 func (p *PythonExtractor) Extract(path string) (*registry.ExtractorResult, error) {
+	// A manifest that cannot be parsed is left out rather than failing the
+	// whole extraction.
+	var warnings []string
+	project, err := readPyproject(path)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("skipping project settings: %v", err))
+	}
+	conda, err := readCondaEnvironment(path)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("skipping conda environment: %v", err))
+	}
+	hatchEnvs, err := readHatchEnvs(path, project)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("skipping Hatch environments: %v", err))
+	}
+	deps := pythonDependencies(path, project, conda)
+	packageManager := detectPythonPackageManager(path, project, conda, hatchEnvs)
 
-	var packageManager string
-	packageManager = detectPythonPackageManager(path)
+	run := pythonRunPrefixes[packageManager]
+	lockfile := registry.FirstExisting(path, pythonLockfiles[packageManager])
+	version, image := detectPythonVersion(path, project, conda)
+	var environment string
+	if packageManager == "conda" {
+		environment = conda.Name
+		run = fmt.Sprintf("conda run --no-capture-output --name %s ", conda.Name)
+		lockfile = conda.File
+		image = condaImage
+	}
 
-	version, image := detectPythonVersion(path)
 	result := &registry.ExtractorResult{
-		Runtime:        "python",
-		RuntimeVersion: version,
-		Image:          image,
-		PackageManager: packageManager,
-		Scripts:        normalizePythonScripts(path, packageManager),
+		Runtime:         "python",
+		RuntimeVersion:  version,
+		RuntimeVersions: detectPythonVersions(path, project),
+		Image:           image,
+		PackageManager:  packageManager,
+		Lockfile:        lockfile,
+		Environment:     environment,
+		Scripts:         normalizePythonScripts(path, project, deps, run, hatchEnvs),
+		Warnings:        warnings,
+	}
+	if install := pythonInstallScript(path, project, packageManager, lockfile, conda); install != "" {
+		result.Scripts["Install"] = install
+	}
+	// Only direct pytest runs are sure to accept the report flags; tox, nox
+	// and Hatch scripts may not pass them on.
+	if test := result.Scripts["Test"]; strings.HasSuffix(test, "pytest") {
+		result.TestReport = detectPythonTestReport(deps, test)
 	}
 
-	return result, nil
-}
-
-func detectPythonPackageManager(path string) string {
-	data, err := os.ReadFile(filepath.Join(path, "pyproject.toml"))
+	services, err := detectComposeServices(path)
 	if err != nil {
-		fmt.Println(err)
-		return "pip"
+		result.Warnings = append(result.Warnings, fmt.Sprintf("skipping services: %v", err))
 	}
+	result.Services = services
 
-	for line := range strings.SplitAfterSeq(string(data), "\n") {
-		if strings.Contains(line, "poetry") {
-			return "poetry"
-		}
-	}
+	env, envWarnings := detectEnvVars(path)
+	result.Env = env
+	result.Warnings = append(result.Warnings, envWarnings...)
 
-	return "uv"
+	return result, nil
 }
 
-func detectPythonVersion(path string) (string, string) {
-	entries, err := os.ReadDir(filepath.Join(path, ".venv"))
-	if err != nil {
-		fmt.Println(err)
+// detectPythonPackageManager picks the manager whose lockfile or
+// configuration the project has, falling back to plain pip.
+func detectPythonPackageManager(path string, project pyproject, conda *condaEnvironment, hatchEnvs map[string]any) string {
+	switch {
+	case registry.FirstExisting(path, "poetry.lock") != "" || project.has("tool", "poetry") || strings.HasPrefix(project.buildBackend(), "poetry."):
+		return "poetry"
+	case registry.FirstExisting(path, "pdm.lock") != "" || usesPDM(project):
+		return "pdm"
+	case registry.FirstExisting(path, "uv.lock") != "" || project.has("tool", "uv"):
+		return "uv"
+	case hatchEnvs != nil:
+		return "hatch"
+	case registry.FirstExisting(path, "Pipfile", "Pipfile.lock") != "":
+		return "pipenv"
+	case conda != nil:
+		return "conda"
 	}
+	return "pip"
+}
 
-	for _, entry := range entries {
-		if after, found := strings.CutPrefix(entry.Name(), "python"); found {
-			return after, "python:" + after + "-slim"
+// usesPDM reports whether [tool.pdm] configures more than the build, which
+// projects built with pdm-backend but managed otherwise also set.
+func usesPDM(project pyproject) bool {
+	for key := range project.table("tool", "pdm") {
+		if key != "build" && key != "version" {
+			return true
 		}
 	}
-	return "3.13", "python:3.13-slim"
+	return false
 }
 
-func normalizePythonScripts(path string, packageManager string) map[string]string {
-
-	scripts := make(map[string]string)
+// pythonInstallScript installs the project's dependencies, including its
+// development dependencies, from the lockfile when there is one.
+func pythonInstallScript(path string, project pyproject, packageManager, lockfile string, conda *condaEnvironment) string {
 	switch packageManager {
 	case "pip":
-		data, err := os.ReadFile(filepath.Join(path, "requirements.txt"))
-		if err != nil {
-			fmt.Println(err)
+		return pipInstallScript(path, project)
+	case "uv":
+		if lockfile != "" {
+			return "uv sync --frozen"
 		}
-		for line := range strings.SplitSeq(string(data), "\n") {
-			if strings.Contains(line, "ruff") {
-				scripts["Lint"] = "ruff check ."
-			}
-			if strings.Contains(line, "pytest") {
-				scripts["Test"] = "pytest"
-			}
-
+		return "uv sync"
+	case "poetry":
+		return "poetry install"
+	case "pdm":
+		if lockfile != "" {
+			return "pdm install --frozen-lockfile"
+		}
+		return "pdm install"
+	case "hatch":
+		return "hatch env create"
+	case "pipenv":
+		if lockfile != "" {
+			return "pipenv install --dev --deploy"
 		}
+		return "pipenv install --dev"
+	case "conda":
+		return fmt.Sprintf("conda env create --file %s --name %s", conda.File, conda.Name)
+	}
+	return ""
+}
 
-	case "uv":
-		data, err := os.ReadFile(filepath.Join(path, "pyproject.toml"))
-		if err != nil {
-			fmt.Println(err)
+// pipDevelopmentGroups are the extras and dependency groups installed along
+// with the project.
+var pipDevelopmentGroups = []string{"dev", "lint", "test", "tests", "typing"}
+
+// pipInstallScript installs the requirement files and, for projects with
+// pyproject.toml or setup.py, the project itself in editable mode with its
+// development extras and dependency groups.
+func pipInstallScript(path string, project pyproject) string {
+	var args []string
+	for _, name := range pythonRequirementFiles {
+		if registry.FirstExisting(path, name) != "" {
+			args = append(args, "-r "+name)
 		}
+	}
 
-		for line := range strings.SplitSeq(string(data), "\n") {
-			if strings.Contains(line, "ruff") {
-				scripts["Lint"] = "uv run ruff check ."
-			}
-			if strings.Contains(line, "pytest") {
-				scripts["Test"] = "uv run pytest"
+	// A setup.cfg or pyproject.toml that only configures tools does not make
+	// the project installable.
+	if project.has("project") || project.has("build-system") || registry.FirstExisting(path, "setup.py") != "" {
+		var extras []string
+		for _, group := range pipDevelopmentGroups {
+			if project.has("project", "optional-dependencies", group) {
+				extras = append(extras, group)
 			}
 		}
-
-	case "poetry":
-		data, err := os.ReadFile(filepath.Join(path, "pyproject.toml"))
-		if err != nil {
-			fmt.Println(err)
+		if len(extras) > 0 {
+			args = append(args, fmt.Sprintf(`-e ".[%s]"`, strings.Join(extras, ",")))
+		} else {
+			args = append(args, "-e .")
+		}
+	}
+	var groups bool
+	for _, group := range pipDevelopmentGroups {
+		if project.has("dependency-groups", group) {
+			args = append(args, "--group "+group)
+			groups = true
 		}
+	}
+
+	if len(args) == 0 {
+		return ""
+	}
+	install := "pip install " + strings.Join(args, " ")
+	if groups {
+		// --group needs pip 25.1, newer than the pip that images and
+		// setup-python ship.
+		install = "python -m pip install --upgrade pip && " + install
+	}
+	return install
+}
+
+// readHatchEnvs returns the environments configured in hatch.toml or under
+// [tool.hatch.envs] in pyproject.toml.
+func readHatchEnvs(path string, project pyproject) (map[string]any, error) {
+	data, err := os.ReadFile(filepath.Join(path, "hatch.toml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return project.table("tool", "hatch", "envs"), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hatch.toml: %w", err)
+	}
+
+	config, err := parseTOML(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hatch.toml: %w", err)
+	}
+	envs := config.table("envs")
+	if envs == nil {
+		// An empty map still marks the project as managed by Hatch.
+		envs = map[string]any{}
+	}
+	return envs, nil
+}
+
+// hatchScripts maps the scripts of Hatch environments to lifecycle keys, so
+// a "test" script in the default environment runs as "hatch run test" and a
+// "check" script in a "lint" environment as "hatch run lint:check".
+func hatchScripts(envs map[string]any) map[string]string {
+	scripts := make(map[string]string)
+	names := slices.Sorted(maps.Keys(envs))
+	// The default environment's scripts take precedence.
+	if i := slices.Index(names, "default"); i > 0 {
+		names = slices.Insert(slices.Delete(names, i, i+1), 0, "default")
+	}
+
+	for _, env := range names {
+		config, _ := envs[env].(map[string]any)
+		envScripts, _ := config["scripts"].(map[string]any)
+		for _, name := range slices.Sorted(maps.Keys(envScripts)) {
+			command := "hatch run " + name
+			if env != "default" {
+				command = "hatch run " + env + ":" + name
+			}
 
-		for line := range strings.SplitSeq(string(data), "\n") {
-			if strings.Contains(line, "ruff") {
-				scripts["Lint"] = "poetry run ruff check ."
+			var key string
+			switch {
+			case name == "test" || name == "tests":
+				key = "Test"
+			case name == "lint" || (env == "lint" && (name == "check" || name == "style" || name == "all")):
+				key = "Lint"
 			}
-			if strings.Contains(line, "pytest") {
-				scripts["Test"] = "poetry run pytest"
+			if _, taken := scripts[key]; key != "" && !taken {
+				scripts[key] = command
 			}
 		}
 	}
-
 	return scripts
 }
+
+// detectPythonTestReport adds pytest's JUnit output and, with pytest-cov
+// installed, a Cobertura coverage report to the test command.
+func detectPythonTestReport(deps map[string]bool, test string) *registry.TestReport {
+	report := &registry.TestReport{
+		Script: test + " --junitxml=report.xml",
+		JUnit:  "report.xml",
+	}
+	if deps["pytest-cov"] {
+		report.Script += " --cov --cov-report=xml:coverage.xml"
+		report.Coverage = "coverage.xml"
+	}
+	return report
+}
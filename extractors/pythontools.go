@@ -0,0 +1,215 @@
+package extractors
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// pythonTool is a tool the Python extractor recognizes. A project uses it
+// when it depends on it: a config file alone does not install the tool.
+type pythonTool struct {
+	key     string
+	name    string
+	command func(path string, project pyproject) string
+}
+
+// pythonTools are listed in the order their commands run within a script.
+var pythonTools = []pythonTool{
+	{key: "Lint", name: "ruff", command: fixedCommand("ruff check .")},
+	{key: "Lint", name: "flake8", command: fixedCommand("flake8 --extend-exclude .venv")},
+	{key: "Lint", name: "pylint", command: fixedCommand("pylint --recursive=y --ignore=.venv .")},
+	{key: "Typecheck", name: "mypy", command: mypyCommand},
+	{key: "Typecheck", name: "pyright", command: fixedCommand("pyright")},
+	{key: "FormatCheck", name: "black", command: fixedCommand("black --check .")},
+	{key: "FormatCheck", name: "isort", command: fixedCommand("isort --check-only .")},
+	{key: "Security", name: "bandit", command: banditCommand},
+	{key: "Test", name: "pytest", command: fixedCommand("pytest")},
+}
+
+// pythonTargetKeys maps the names of tox environments and nox sessions to
+// the script they stand for.
+var pythonTargetKeys = map[string]string{
+	"lint":      "Lint",
+	"style":     "Lint",
+	"flake8":    "Lint",
+	"pylint":    "Lint",
+	"ruff":      "Lint",
+	"type":      "Typecheck",
+	"types":     "Typecheck",
+	"typing":    "Typecheck",
+	"typecheck": "Typecheck",
+	"mypy":      "Typecheck",
+	"pyright":   "Typecheck",
+	"security":  "Security",
+	"bandit":    "Security",
+	"py":        "Test",
+	"test":      "Test",
+	"tests":     "Test",
+}
+
+// noxSession matches the functions decorated as nox sessions, along with a
+// name= argument that renames them.
+var noxSession = regexp.MustCompile(`@(?:nox\.)?session\b(?:\(([^)]*)\))?\s*\n\s*def\s+(\w+)`)
+
+var noxSessionName = regexp.MustCompile(`name\s*=\s*["']([\w-]+)["']`)
+
+func fixedCommand(command string) func(string, pyproject) string {
+	return func(string, pyproject) string {
+		return command
+	}
+}
+
+func mypyCommand(path string, project pyproject) string {
+	if project.has("tool", "mypy", "files") {
+		return "mypy"
+	}
+	return "mypy " + pythonSourceDir(path)
+}
+
+func banditCommand(path string, project pyproject) string {
+	command := "bandit -r " + pythonSourceDir(path)
+	if project.has("tool", "bandit") {
+		command = "bandit -c pyproject.toml -r " + pythonSourceDir(path)
+	}
+	if pythonSourceDir(path) == "." {
+		command += " -x ./.venv,./tests"
+	}
+	return command
+}
+
+// pythonSourceDir returns "src" for projects with a src layout and the
+// project root otherwise.
+func pythonSourceDir(path string) string {
+	if info, err := os.Stat(filepath.Join(path, "src")); err == nil && info.IsDir() {
+		return "src"
+	}
+	return "."
+}
+
+// normalizePythonScripts derives the lint, type check, format check,
+// security and test commands from the tools the project uses, run with the
+// package manager's run prefix. Scripts of Hatch environments take
+// precedence, and tox environments and nox sessions fill in the rest.
+func normalizePythonScripts(path string, project pyproject, deps map[string]bool, run string, hatchEnvs map[string]any) map[string]string {
+	scripts := hatchScripts(hatchEnvs)
+
+	commands := make(map[string][]string)
+	for _, tool := range pythonTools {
+		if !deps[tool.name] {
+			continue
+		}
+		commands[tool.key] = append(commands[tool.key], run+tool.command(path, project))
+		if tool.name == "ruff" && project.has("tool", "ruff", "format") {
+			commands["FormatCheck"] = append(commands["FormatCheck"], run+"ruff format --check .")
+		}
+	}
+	for key, list := range commands {
+		if _, ok := scripts[key]; !ok {
+			scripts[key] = strings.Join(list, " && ")
+		}
+	}
+
+	addTarget := func(name, command string) {
+		if key := pythonTargetKeys[name]; key != "" {
+			if _, ok := scripts[key]; !ok {
+				scripts[key] = run + command
+			}
+		}
+	}
+	if deps["tox"] {
+		for _, env := range toxEnvs(path) {
+			addTarget(env, "tox -e "+env)
+		}
+	}
+	if deps["nox"] {
+		for _, session := range noxSessions(path) {
+			addTarget(session, "nox -s "+session)
+		}
+	}
+	return scripts
+}
+
+// toxEnvs returns the environments of tox.ini, with those testing a specific
+// interpreter reported once as "py", which tox runs with the current one.
+func toxEnvs(path string) []string {
+	var envs []string
+	for _, name := range toxEnvNames(path) {
+		if toxPythonFactor.MatchString(strings.Split(name, "-")[0]) {
+			name = "py"
+		}
+		if !slices.Contains(envs, name) {
+			envs = append(envs, name)
+		}
+	}
+	return envs
+}
+
+// toxEnvNames returns the environments in the tox.ini envlist, with
+// generative names expanded, followed by those with a [testenv:name] section.
+func toxEnvNames(path string) []string {
+	file, err := os.Open(filepath.Join(path, "tox.ini"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	// The envlist may continue on indented lines below the key.
+	var envlist, envs []string
+	var section string
+	var inEnvlist bool
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section, inEnvlist = line, false
+			if name, found := strings.CutPrefix(strings.Trim(line, "[]"), "testenv:"); found {
+				envs = append(envs, name)
+			}
+			continue
+		}
+		if inEnvlist && (raw[0] == ' ' || raw[0] == '\t') {
+			envlist = append(envlist, line)
+			continue
+		}
+		inEnvlist = false
+
+		key, value, found := strings.Cut(line, "=")
+		if key = strings.TrimSpace(key); section == "[tox]" && found && (key == "envlist" || key == "env_list") {
+			envlist = append(envlist, strings.TrimSpace(value))
+			inEnvlist = true
+		}
+	}
+
+	var names []string
+	for _, env := range expandBraces(strings.Join(envlist, ",")) {
+		names = append(names, strings.FieldsFunc(env, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })...)
+	}
+	return append(names, envs...)
+}
+
+// noxSessions returns the session names of noxfile.py.
+func noxSessions(path string) []string {
+	data, err := os.ReadFile(filepath.Join(path, "noxfile.py"))
+	if err != nil {
+		return nil
+	}
+
+	var sessions []string
+	for _, match := range noxSession.FindAllStringSubmatch(string(data), -1) {
+		name := match[2]
+		if renamed := noxSessionName.FindStringSubmatch(match[1]); renamed != nil {
+			name = renamed[1]
+		}
+		sessions = append(sessions, name)
+	}
+	return sessions
+}
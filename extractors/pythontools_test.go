@@ -0,0 +1,91 @@
+package extractors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPythonExtractTools(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		install string
+		scripts map[string]string
+	}{
+		{
+			name: "configured but not installed",
+			files: map[string]string{
+				"requirements.txt": "django\n",
+				"setup.cfg":        "[flake8]\nmax-line-length = 100\n\n[tool:pytest]\ntestpaths = tests\n",
+				"tox.ini":          "[mypy]\nstrict = true\n",
+				"ruff.toml":        "line-length = 100\n",
+				"pyproject.toml":   "[tool.black]\nline-length = 100\n",
+			},
+			install: "pip install -r requirements.txt",
+			scripts: map[string]string{},
+		},
+		{
+			name: "installed from requirements",
+			files: map[string]string{
+				"requirements.txt":     "django\n",
+				"requirements-dev.txt": "ruff\npytest>=8\n",
+				"setup.cfg":            "[tool:pytest]\ntestpaths = tests\n",
+			},
+			install: "pip install -r requirements.txt -r requirements-dev.txt",
+			scripts: map[string]string{"Lint": "ruff check .", "Test": "pytest"},
+		},
+		{
+			name: "installable project",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"app\"\n\n[project.optional-dependencies]\ndev = [\"mypy\"]\n",
+			},
+			install: `pip install -e ".[dev]"`,
+			scripts: map[string]string{"Typecheck": "mypy ."},
+		},
+		{
+			name: "build system only",
+			files: map[string]string{
+				"pyproject.toml": "[build-system]\nrequires = [\"setuptools\"]\nbuild-backend = \"setuptools.build_meta\"\n",
+				"setup.cfg":      "[metadata]\nname = app\n",
+			},
+			install: "pip install -e .",
+			scripts: map[string]string{},
+		},
+		{
+			name: "setup.py",
+			files: map[string]string{
+				"setup.py": "from setuptools import setup\n\nsetup()\n",
+			},
+			install: "pip install -e .",
+			scripts: map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range test.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			result, err := (&PythonExtractor{}).Extract(dir)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if install := result.Scripts["Install"]; install != test.install {
+				t.Errorf("Install = %q, want %q", install, test.install)
+			}
+			delete(result.Scripts, "Install")
+			if len(result.Scripts) != len(test.scripts) {
+				t.Errorf("Scripts = %q, want %q", result.Scripts, test.scripts)
+			}
+			for key, want := range test.scripts {
+				if got := result.Scripts[key]; got != want {
+					t.Errorf("Scripts[%q] = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package extractors
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pythonReleases are the Python release lines that still receive fixes,
+// oldest first. Version ranges resolve against them.
+var pythonReleases = []string{"3.10", "3.11", "3.12", "3.13", "3.14"}
+
+// pythonVersionPin matches a concrete version such as "3.12" or "3.12.4".
+var pythonVersionPin = regexp.MustCompile(`^\d+\.\d+(\.\d+)?$`)
+
+// toxPythonFactor matches the interpreter factor of a tox environment, e.g.
+// "py311" in "py311-django42".
+var toxPythonFactor = regexp.MustCompile(`^py3(\d+)$`)
+
+// detectPythonVersion returns the version to run, preferring an exact pin
+// from .python-version, runtime.txt, the Pipfile or the conda environment
+// over the newest release the project's version range or classifiers allow.
+// A range that excludes every release in the table resolves to the nearest
+// release line it allows.
+func detectPythonVersion(path string, project pyproject, conda *condaEnvironment) (string, string) {
+	version := pythonPinnedVersion(path, conda)
+	if version == "" {
+		if versions := pythonSupportedVersions(path, project); len(versions) > 0 {
+			version = versions[len(versions)-1]
+		} else if specifier := pythonSpecifier(project); specifier != "" {
+			version = pythonNearestRelease(specifier)
+		}
+	}
+	if version == "" {
+		version = pythonReleases[len(pythonReleases)-1]
+	}
+	return version, "python:" + version + "-slim"
+}
+
+// detectPythonVersions returns the releases the project supports for a
+// version matrix, or nil when fewer than two match.
+func detectPythonVersions(path string, project pyproject) []string {
+	versions := pythonSupportedVersions(path, project)
+	if len(versions) < 2 {
+		return nil
+	}
+	return versions
+}
+
+func pythonPinnedVersion(path string, conda *condaEnvironment) string {
+	// pyenv allows several versions, one per line, the first being the default.
+	if data, err := os.ReadFile(filepath.Join(path, ".python-version")); err == nil {
+		for line := range strings.SplitSeq(string(data), "\n") {
+			if line = strings.TrimSpace(line); pythonVersionPin.MatchString(line) {
+				return line
+			}
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(path, "runtime.txt")); err == nil {
+		version := strings.TrimPrefix(strings.TrimSpace(string(data)), "python-")
+		if pythonVersionPin.MatchString(version) {
+			return version
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(path, "Pipfile")); err == nil {
+		if pipfile, err := parseTOML(string(data)); err == nil {
+			for _, key := range []string{"python_full_version", "python_version"} {
+				if version := pipfile.str("requires", key); pythonVersionPin.MatchString(version) {
+					return version
+				}
+			}
+		}
+	}
+	return conda.pythonVersion()
+}
+
+// pythonSupportedVersions resolves the first declared range of supported
+// versions: requires-python, Poetry's python dependency, the tox envlist,
+// then the trove classifiers.
+func pythonSupportedVersions(path string, project pyproject) []string {
+	if specifier := pythonSpecifier(project); specifier != "" {
+		return pythonVersionsMatching(specifier)
+	}
+	if versions := toxVersions(path); len(versions) > 0 {
+		return versions
+	}
+
+	classifiers := append(project.strings("project", "classifiers"), project.strings("tool", "poetry", "classifiers")...)
+	var versions []string
+	for _, release := range pythonReleases {
+		if slices.Contains(classifiers, "Programming Language :: Python :: "+release) {
+			versions = append(versions, release)
+		}
+	}
+	return versions
+}
+
+// pythonSpecifier returns the declared version range as a PEP 440 specifier,
+// taken from requires-python or Poetry's python dependency.
+func pythonSpecifier(project pyproject) string {
+	if specifier := project.str("project", "requires-python"); specifier != "" {
+		return specifier
+	}
+	if constraint := project.str("tool", "poetry", "dependencies", "python"); constraint != "" {
+		return poetryConstraintSpecifier(constraint)
+	}
+	return ""
+}
+
+// pythonNearestRelease returns the release line closest to pythonReleases
+// that satisfies specifier, for ranges such as "<3.10" that only allow
+// releases past their end of life or not yet in the table. Older lines win
+// ties, since their images exist.
+func pythonNearestRelease(specifier string) string {
+	oldest, _ := strconv.Atoi(strings.TrimPrefix(pythonReleases[0], "3."))
+	newest, _ := strconv.Atoi(strings.TrimPrefix(pythonReleases[len(pythonReleases)-1], "3."))
+	for distance := 1; distance <= max(oldest, 10); distance++ {
+		for _, minor := range []int{oldest - distance, newest + distance} {
+			if minor < 0 {
+				continue
+			}
+			if release := "3." + strconv.Itoa(minor); pythonSpecifierAllows(release, specifier) {
+				return release
+			}
+		}
+	}
+	return ""
+}
+
+// toxVersions returns the releases the tox.ini envlist tests against.
+func toxVersions(path string) []string {
+	found := make(map[string]bool)
+	for _, name := range toxEnvNames(path) {
+		for factor := range strings.SplitSeq(name, "-") {
+			if match := toxPythonFactor.FindStringSubmatch(factor); match != nil {
+				found["3."+match[1]] = true
+			}
+		}
+	}
+
+	var versions []string
+	for _, release := range pythonReleases {
+		if found[release] {
+			versions = append(versions, release)
+		}
+	}
+	return versions
+}
+
+// expandBraces expands tox's generative names, so "py3{11,12}-django"
+// becomes "py311-django" and "py312-django".
+func expandBraces(s string) []string {
+	open := strings.IndexByte(s, '{')
+	if open < 0 {
+		return []string{s}
+	}
+	end := strings.IndexByte(s[open:], '}')
+	if end < 0 {
+		return []string{s}
+	}
+	end += open
+
+	var expanded []string
+	for alternative := range strings.SplitSeq(s[open+1:end], ",") {
+		expanded = append(expanded, expandBraces(s[:open]+strings.TrimSpace(alternative)+s[end+1:])...)
+	}
+	return expanded
+}
+
+// poetryConstraintSpecifier turns a Poetry constraint such as "^3.10" or
+// "~3.11" into the equivalent PEP 440 specifier.
+func poetryConstraintSpecifier(constraint string) string {
+	var alternatives []string
+	for alternative := range strings.SplitSeq(constraint, "||") {
+		var clauses []string
+		for clause := range strings.SplitSeq(alternative, ",") {
+			clause = strings.TrimSpace(clause)
+			parts := strings.Split(strings.TrimLeft(clause, "^~"), ".")
+			major, _ := strconv.Atoi(parts[0])
+			minor := 0
+			if len(parts) > 1 {
+				minor, _ = strconv.Atoi(parts[1])
+			}
+
+			switch {
+			case clause == "*" || clause == "":
+			case strings.HasPrefix(clause, "^"):
+				clauses = append(clauses, ">="+clause[1:], "<"+strconv.Itoa(major+1))
+			case strings.HasPrefix(clause, "~") && !strings.HasPrefix(clause, "~=") && len(parts) == 1:
+				// "~3" allows any 3.x release, like "^3".
+				clauses = append(clauses, ">="+clause[1:], "<"+strconv.Itoa(major+1))
+			case strings.HasPrefix(clause, "~") && !strings.HasPrefix(clause, "~="):
+				clauses = append(clauses, ">="+clause[1:], "<"+strconv.Itoa(major)+"."+strconv.Itoa(minor+1))
+			default:
+				clauses = append(clauses, clause)
+			}
+		}
+		alternatives = append(alternatives, strings.Join(clauses, ","))
+	}
+	return strings.Join(alternatives, "||")
+}
+
+// pythonVersionsMatching returns the releases that satisfy specifier, whose
+// "||" alternatives are only used by Poetry constraints.
+func pythonVersionsMatching(specifier string) []string {
+	var versions []string
+	for _, release := range pythonReleases {
+		if pythonSpecifierAllows(release, specifier) {
+			versions = append(versions, release)
+		}
+	}
+	return versions
+}
+
+// pythonSpecifierAllows reports whether release satisfies one of the "||"
+// alternatives of specifier.
+func pythonSpecifierAllows(release, specifier string) bool {
+	for alternative := range strings.SplitSeq(specifier, "||") {
+		if pythonReleaseAllowed(release, alternative) {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonReleaseAllowed reports whether any version of a minor release line
+// such as "3.11" satisfies a PEP 440 specifier like ">=3.9,<3.13".
+func pythonReleaseAllowed(release, specifier string) bool {
+	for clause := range strings.SplitSeq(specifier, ",") {
+		clause = strings.TrimSpace(clause)
+		i := strings.IndexFunc(clause, unicode.IsDigit)
+		if i < 0 {
+			continue
+		}
+		op := strings.TrimSpace(clause[:i])
+		parts := strings.Split(strings.TrimSuffix(clause[i:], ".*"), ".")
+		bound := strings.Join(parts[:min(len(parts), 2)], ".")
+		patch := len(parts) > 2
+		cmp := compareVersions(release, bound)
+
+		var ok bool
+		switch op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0 || (patch && cmp == 0)
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0 || (patch && cmp == 0)
+		case "!=":
+			ok = cmp != 0 || patch
+		case "~=":
+			if patch {
+				ok = cmp == 0
+			} else {
+				ok = cmp >= 0 && compareVersions(release, parts[0]+".999") <= 0
+			}
+		default:
+			ok = cmp == 0 || (len(parts) == 1 && strings.HasPrefix(release, parts[0]+"."))
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(as), len(bs)) {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
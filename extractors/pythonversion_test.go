@@ -0,0 +1,41 @@
+package extractors
+
+import "testing"
+
+func TestPoetryConstraintSpecifier(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       string
+	}{
+		{"^3.10", ">=3.10,<4"},
+		{"~3.11", ">=3.11,<3.12"},
+		{"~3.11.2", ">=3.11.2,<3.12"},
+		{"~3", ">=3,<4"},
+		{">=3.9,<3.13", ">=3.9,<3.13"},
+		{"^3.8 || ^4.0", ">=3.8,<4||>=4.0,<5"},
+		{"*", ""},
+	}
+	for _, test := range tests {
+		if got := poetryConstraintSpecifier(test.constraint); got != test.want {
+			t.Errorf("poetryConstraintSpecifier(%q) = %q, want %q", test.constraint, got, test.want)
+		}
+	}
+}
+
+func TestPythonNearestRelease(t *testing.T) {
+	tests := []struct {
+		specifier string
+		want      string
+	}{
+		{"<3.10", "3.9"},
+		{">=3.6,<3.8", "3.7"},
+		{"==3.8.*", "3.8"},
+		{">=3.15", "3.15"},
+		{">=4", ""},
+	}
+	for _, test := range tests {
+		if got := pythonNearestRelease(test.specifier); got != test.want {
+			t.Errorf("pythonNearestRelease(%q) = %q, want %q", test.specifier, got, test.want)
+		}
+	}
+}
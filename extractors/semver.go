@@ -0,0 +1,219 @@
+package extractors
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// semverVersion is a major, minor and patch triple. Prerelease tags are
+// ignored, which is precise enough to pick a runtime release.
+type semverVersion [3]int
+
+func (v semverVersion) less(o semverVersion) bool {
+	for i := range v {
+		if v[i] != o[i] {
+			return v[i] < o[i]
+		}
+	}
+	return false
+}
+
+// semverUnbounded is the upper end of ranges without an upper limit.
+var semverUnbounded = semverVersion{math.MaxInt32, 0, 0}
+
+// semverInterval is the half-open interval [min, max) of versions a
+// comparator set allows. Every comparator, including inclusive upper bounds
+// such as "<=2.3.4", is rewritten to this form.
+type semverInterval struct {
+	min, max semverVersion
+}
+
+func (i semverInterval) empty() bool {
+	return !i.min.less(i.max)
+}
+
+func (i semverInterval) intersect(o semverInterval) semverInterval {
+	if i.min.less(o.min) {
+		i.min = o.min
+	}
+	if o.max.less(i.max) {
+		i.max = o.max
+	}
+	return i
+}
+
+// semverRange is a node-semver range: a union of comparator sets such as
+// ">=18 <21 || ^22".
+type semverRange []semverInterval
+
+// parseSemverRange parses the range syntax of package.json: comparators,
+// caret and tilde ranges, X-ranges, partial versions and hyphen ranges. It
+// reports false when a comparator is not a version.
+func parseSemverRange(s string) (semverRange, bool) {
+	var r semverRange
+	for set := range strings.SplitSeq(s, "||") {
+		interval, ok := parseSemverSet(set)
+		if !ok {
+			return nil, false
+		}
+		if !interval.empty() {
+			r = append(r, interval)
+		}
+	}
+	return r, true
+}
+
+func parseSemverSet(set string) (semverInterval, bool) {
+	interval := semverInterval{max: semverUnbounded}
+	fields := strings.Fields(set)
+
+	// "1.2 - 2.3" is inclusive on both ends.
+	if len(fields) == 3 && fields[1] == "-" {
+		lower, _, okLower := parseSemverPartial(fields[0])
+		upper, parts, okUpper := parseSemverPartial(fields[2])
+		if !okLower || !okUpper {
+			return interval, false
+		}
+		interval.min, interval.max = lower, semverNext(upper, parts)
+		return interval, true
+	}
+
+	// Operators may be separated from their version by spaces.
+	for i := 0; i < len(fields); i++ {
+		comparator := fields[i]
+		if strings.Trim(comparator, "<>=~^") == "" && i+1 < len(fields) {
+			i++
+			comparator += fields[i]
+		}
+		c, ok := parseSemverComparator(comparator)
+		if !ok {
+			return interval, false
+		}
+		interval = interval.intersect(c)
+	}
+	return interval, true
+}
+
+func parseSemverComparator(comparator string) (semverInterval, bool) {
+	op := comparator[:len(comparator)-len(strings.TrimLeft(comparator, "<>=~^"))]
+	version, parts, ok := parseSemverPartial(comparator[len(op):])
+	if !ok {
+		return semverInterval{}, false
+	}
+	next := semverNext(version, parts)
+
+	switch op {
+	case "", "=":
+		return semverInterval{version, next}, true
+	case ">=":
+		return semverInterval{version, semverUnbounded}, true
+	case ">":
+		return semverInterval{next, semverUnbounded}, true
+	case "<":
+		return semverInterval{semverVersion{}, version}, true
+	case "<=":
+		return semverInterval{semverVersion{}, next}, true
+	case "~", "~>":
+		return semverInterval{version, semverNext(version, min(parts, 2))}, true
+	case "^":
+		// The caret allows changes that do not touch the leftmost non-zero
+		// part, or the last given part when all are zero.
+		keep := parts
+		for i := range parts {
+			if version[i] != 0 {
+				keep = i + 1
+				break
+			}
+		}
+		return semverInterval{version, semverNext(version, keep)}, true
+	}
+	return semverInterval{}, false
+}
+
+// parseSemverPartial parses a possibly partial version such as "v18",
+// "20.x" or "1.2.3-beta.1", returning how many parts were given. "*" and
+// "x" give no parts.
+func parseSemverPartial(s string) (semverVersion, int, bool) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "="), "v")
+	s, _, _ = strings.Cut(s, "+")
+	s, _, _ = strings.Cut(s, "-")
+
+	var version semverVersion
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return version, 0, true
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return version, 0, false
+	}
+	for i, part := range parts {
+		if part == "*" || part == "x" || part == "X" {
+			return version, i, true
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, 0, false
+		}
+		version[i] = n
+	}
+	return version, len(parts), true
+}
+
+// semverNext returns the first version after every version that starts
+// with the given parts, so "1.2" yields "1.3.0".
+func semverNext(version semverVersion, parts int) semverVersion {
+	if parts == 0 {
+		return semverUnbounded
+	}
+	next := semverVersion{}
+	copy(next[:parts], version[:parts])
+	next[parts-1]++
+	return next
+}
+
+// allowsMajor reports whether any release of a major version line satisfies
+// the range.
+func (r semverRange) allowsMajor(major int) bool {
+	line := semverInterval{semverVersion{major, 0, 0}, semverVersion{major + 1, 0, 0}}
+	for _, interval := range r {
+		if !interval.intersect(line).empty() {
+			return true
+		}
+	}
+	return false
+}
+
+// lowestMajor returns the major version of the lowest release the range
+// allows.
+func (r semverRange) lowestMajor() (int, bool) {
+	if len(r) == 0 {
+		return 0, false
+	}
+	lowest := r[0].min
+	for _, interval := range r[1:] {
+		if interval.min.less(lowest) {
+			lowest = interval.min
+		}
+	}
+	return lowest[0], true
+}
+
+// highestMajor returns the major version of the highest release the range
+// allows, reporting false when the range has no upper bound.
+func (r semverRange) highestMajor() (int, bool) {
+	highest, found := 0, false
+	for _, interval := range r {
+		if interval.max == semverUnbounded {
+			return 0, false
+		}
+		// The upper end is exclusive, so "<16.0.0" tops out in 15.x.
+		major := interval.max[0]
+		if interval.max[1] == 0 && interval.max[2] == 0 {
+			major--
+		}
+		highest, found = max(highest, major), true
+	}
+	return highest, found
+}
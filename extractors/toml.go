@@ -0,0 +1,54 @@
+package extractors
+
+import "github.com/BurntSushi/toml"
+
+// tomlDocument is a decoded TOML file with helpers to look up nested keys.
+type tomlDocument map[string]any
+
+func (d tomlDocument) lookup(keys ...string) any {
+	var value any = map[string]any(d)
+	for _, key := range keys {
+		table, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = table[key]
+	}
+	return value
+}
+
+// has reports whether the table or key at keys exists, e.g. [tool.poetry].
+func (d tomlDocument) has(keys ...string) bool {
+	return d.lookup(keys...) != nil
+}
+
+func (d tomlDocument) table(keys ...string) map[string]any {
+	table, _ := d.lookup(keys...).(map[string]any)
+	return table
+}
+
+func (d tomlDocument) str(keys ...string) string {
+	s, _ := d.lookup(keys...).(string)
+	return s
+}
+
+// strings returns the strings of the array at keys, skipping other values.
+func (d tomlDocument) strings(keys ...string) []string {
+	list, _ := d.lookup(keys...).([]any)
+	var values []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
+
+// parseTOML decodes a TOML file into nested maps.
+func parseTOML(data string) (tomlDocument, error) {
+	document := make(tomlDocument)
+	if _, err := toml.Decode(data, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
@@ -0,0 +1,76 @@
+package extractors
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	document, err := parseTOML(`
+[project]
+name = "app"
+dependencies = ["django>=4.2", 'pytest']
+optional-dependencies.test = ["coverage"]
+
+[tool.poetry.dependencies]
+python = "^3.11"
+
+[[tool.uv.index]]
+name = "internal"
+`)
+	if err != nil {
+		t.Fatalf("parseTOML() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"string", document.str("project", "name"), "app"},
+		{"nested string", document.str("tool", "poetry", "dependencies", "python"), "^3.11"},
+		{"missing string", document.str("project", "version"), ""},
+		{"string of a table", document.str("tool", "poetry"), ""},
+		{"has table", document.has("tool", "poetry"), true},
+		{"has dotted key", document.has("project", "optional-dependencies", "test"), true},
+		{"has array of tables", document.has("tool", "uv", "index"), true},
+		{"missing table", document.has("tool", "pdm"), false},
+		{"table", len(document.table("tool", "poetry")), 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.got != test.want {
+				t.Errorf("got %v, want %v", test.got, test.want)
+			}
+		})
+	}
+
+	if got, want := document.strings("project", "dependencies"), []string{"django>=4.2", "pytest"}; !slices.Equal(got, want) {
+		t.Errorf("strings() = %q, want %q", got, want)
+	}
+}
+
+func TestParseTOMLError(t *testing.T) {
+	for _, data := range []string{"[tool.poetry\n", "name = \n", "a = 1\na = 2\n"} {
+		if _, err := parseTOML(data); err == nil {
+			t.Errorf("parseTOML(%q) succeeded, want an error", data)
+		}
+	}
+}
+
+func TestReadPyprojectInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte("[project\nname = \"app\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&PythonExtractor{}).Extract(dir)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(result.Warnings) != 1 {
+		t.Errorf("Warnings = %q, want one warning", result.Warnings)
+	}
+}
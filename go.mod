@@ -3,6 +3,7 @@ module github.com/zraisan/AutoFlow
 go 1.25.4
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/charmbracelet/bubbletea v1.3.10
 	github.com/charmbracelet/fang v0.4.4
 	github.com/spf13/cobra v1.10.2
@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FirstExisting returns the first of names that exists in path, or an empty
+// string when none do.
+func FirstExisting(path string, names ...string) string {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(path, name)); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// GoBinaries lists the names `go build -o bin/ ./...` gives to the main
+// packages of the module rooted at path.
+func GoBinaries(path string) []string {
+	module := ""
+	if data, err := os.ReadFile(filepath.Join(path, "go.mod")); err == nil {
+		for line := range strings.SplitSeq(string(data), "\n") {
+			if after, found := strings.CutPrefix(strings.TrimSpace(line), "module "); found {
+				module = strings.Trim(strings.TrimSpace(after), `"`)
+				break
+			}
+		}
+	}
+
+	var binaries []string
+	filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			base := entry.Name()
+			if file != path && (strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_") || base == "vendor" || base == "testdata" || base == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") || !isMainPackage(file) {
+			return nil
+		}
+
+		dir := filepath.Dir(file)
+		binary := filepath.Base(dir)
+		if dir == filepath.Clean(path) {
+			binary = filepath.Base(module)
+		}
+		if !slices.Contains(binaries, binary) {
+			binaries = append(binaries, binary)
+		}
+		return nil
+	})
+
+	return binaries
+}
+
+func isMainPackage(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if after, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "package "); found {
+			return strings.TrimSpace(after) == "main"
+		}
+	}
+	return false
+}
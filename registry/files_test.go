@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGoBinaries(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":              "module example.com/app\n",
+		"main.go":             "package main\n",
+		"cmd/worker/main.go":  "package main\n",
+		"internal/lib/lib.go": "package lib\n",
+		"vendor/x/main.go":    "package main\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The root binary is named after the module however the path is spelled.
+	for _, path := range []string{dir, dir + "/", dir + "/.", filepath.Join(dir, "cmd") + "/.."} {
+		got := GoBinaries(path)
+		slices.Sort(got)
+		if want := []string{"app", "worker"}; !slices.Equal(got, want) {
+			t.Errorf("GoBinaries(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package registry
 
+import (
+	"fmt"
+	"strings"
+)
+
 var (
 	extractors []Extractor
 	executors  []Executor
@@ -36,3 +41,21 @@ func GetExtractor(index int) Extractor {
 func GetExecutor(index int) Executor {
 	return executors[index]
 }
+
+func FindExtractor(name string) (Extractor, error) {
+	for _, e := range extractors {
+		if strings.EqualFold(e.Name(), name) {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown extractor %q, expected one of: %s", name, strings.Join(ExtractorNames(), ", "))
+}
+
+func FindExecutor(name string) (Executor, error) {
+	for _, e := range executors {
+		if strings.EqualFold(e.Name(), name) {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown executor %q, expected one of: %s", name, strings.Join(ExecutorNames(), ", "))
+}
@@ -1,11 +1,116 @@
 package registry
 
 type ExtractorResult struct {
-	Runtime        string
-	RuntimeVersion string
-	Image          string
-	PackageManager string
-	Scripts        map[string]string
+	Runtime         string
+	RuntimeVersion  string
+	RuntimeVersions []string
+	Image           string
+	PackageManager  string
+	// PackageManagerVersion is the exact version the project pins its
+	// package manager to, or empty when it leaves the version open.
+	PackageManagerVersion string
+	Lockfile              string
+	Scripts               map[string]string
+	Artifacts             []string
+	TestReport            *TestReport
+	Services              []Service
+	Env                   []EnvVar
+	// Environment names the environment the scripts run in, such as the
+	// conda environment created from the Lockfile.
+	Environment string
+	// Warnings are the problems the extractor worked around, such as a
+	// compose file it could not read.
+	Warnings []string
+}
+
+// TestReport describes a variant of the Test script that leaves a JUnit XML
+// report and, when Coverage is set, a Cobertura coverage report behind.
+type TestReport struct {
+	Script   string
+	JUnit    string
+	Coverage string
+}
+
+// Service is a container, such as a database, that the tests talk to while
+// they run. Services are read from the project's compose file.
+type Service struct {
+	Name        string
+	Image       string
+	Ports       []ServicePort
+	Env         map[string]string
+	HealthCheck *HealthCheck
+}
+
+// ServicePort maps a port of the service container to a port on the host.
+type ServicePort struct {
+	Host      int
+	Container int
+}
+
+// HealthCheck is the command that tells when a service is ready.
+type HealthCheck struct {
+	Command  string
+	Interval string
+	Timeout  string
+	Retries  int
+}
+
+// EnvVar is an environment variable the project reads at runtime, which the
+// pipeline has to provide, usually from a secret. Source is the file it was
+// found in.
+type EnvVar struct {
+	Name        string
+	Description string
+	Source      string
+}
+
+// Options carries the choices made in the TUI or on the command line to an
+// Executor.
+type Options struct {
+	Name     string
+	OS       []string
+	FailFast bool
+	Triggers Triggers
+	Layout   string
+
+	// DeployWhen controls when deploy jobs start: manually (the default),
+	// after DeployDelay, or as soon as the previous jobs succeed.
+	DeployWhen  string
+	DeployDelay string
+
+	// Permissions, TimeoutMinutes, ConcurrencyGroup and CancelInProgress
+	// override the hardened defaults of the generated workflow.
+	Permissions      map[string]string
+	TimeoutMinutes   int
+	ConcurrencyGroup string
+	CancelInProgress string
+}
+
+// Job layouts supported by Options.Layout. LayoutSingle runs every script in
+// one job; LayoutSplit creates separate lint, test, build and deploy jobs.
+const (
+	LayoutSingle = "single"
+	LayoutSplit  = "split"
+)
+
+// Deploy modes supported by Options.DeployWhen.
+const (
+	DeployManual    = "manual"
+	DeployDelayed   = "delayed"
+	DeployOnSuccess = "on_success"
+)
+
+// Triggers selects the events that start a pipeline. Branches defaults to
+// the repository's default branch when empty.
+type Triggers struct {
+	Push        bool
+	Branches    []string
+	PullRequest bool
+	Tags        []string
+	Schedule    string
+	Dispatch    bool
+	Inputs      map[string]string
+	Paths       []string
 }
 
 type Extractor interface {
@@ -15,5 +120,5 @@ type Extractor interface {
 
 type Executor interface {
 	Name() string
-	Generate(result *ExtractorResult, path, name string) (string, error)
+	Generate(result *ExtractorResult, path string, opts *Options) (string, error)
 }